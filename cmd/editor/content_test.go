@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bradbev/flatland/cmd/editor/edtest"
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestContentRoundTrip loads every asset in the content folder and saves
+// it again, the saved file must be byte for byte the same.  After a change
+// to the save format, content is brought up to date with flatcmd.Upgrade
+// and -resave.
+func TestContentRoundTrip(t *testing.T) {
+	content := os.DirFS("../../content")
+	out := t.TempDir()
+
+	m := asset.NewManager()
+	m.RegisterAsset(EditTest{})
+	m.RegisterAsset(EditTest2{})
+	m.RegisterAsset(actorTest{})
+	m.RegisterAsset(flat.TestMouseHandler{})
+	m.RegisterAsset(edtest.UiTestActor{})
+	flat.RegisterAllFlatTypesWithManager(m)
+	m.RegisterFileSystem(content, 0)
+	m.RegisterWritableFileSystem(asset.NewWritableFS(asset.Path(out)))
+
+	registered := map[string]bool{}
+	for _, desc := range m.GetAssetDescriptors() {
+		registered[desc.FullName] = true
+	}
+
+	err := fs.WalkDir(content, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := fs.ReadFile(content, path)
+		if err != nil {
+			return err
+		}
+		var container struct{ Type string }
+		if json.Unmarshal(data, &container) != nil || container.Type == "" {
+			// not an asset
+			return nil
+		}
+		if !registered[container.Type] {
+			t.Logf("skipping %s, %s is not registered", path, container.Type)
+			return nil
+		}
+
+		loaded, err := m.Load(asset.Path(path))
+		if !assert.NoError(t, err, path) {
+			return nil
+		}
+		assert.NoError(t, m.Save(asset.Path(path), loaded), path)
+		saved, err := os.ReadFile(filepath.Join(out, path))
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, string(data), string(saved), path)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
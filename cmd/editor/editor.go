@@ -31,7 +31,7 @@ func main() {
 	gg := &G{
 		mgr:    mgr,
 		dscale: ebiten.DeviceScaleFactor(),
-		ed:     editor.New("./content", mgr),
+		ed:     editor.New("./content", mgr, nil),
 	}
 
 	edtest.TreeTestInit()
@@ -22,7 +22,7 @@ func main() {
 
 	gg := &G{
 		mgr: mgr,
-		ed:  editor.New("./content", mgr),
+		ed:  editor.New("./content", mgr, nil),
 	}
 
 	flat.RegisterAllFlatTypes()
@@ -32,7 +32,10 @@ func main() {
 
 	gg.ed.StartGameCallback(func() ebiten.Game {
 		flow, err := asset.Load("gameflow.json")
-		flat.Check(err)
+		gg.ed.RaiseError(err)
+		if flow == nil {
+			return nil
+		}
 		game := &fruitroids.Fruitroids{}
 		game.BeginPlay(flow.(*fruitroids.GameFlow))
 		return game
@@ -0,0 +1,18 @@
+// flatland-check reports problems in the fruitroids content, such as
+// missing files and unregistered types.  Run it from examples/fruitroids,
+// it exits with 1 if any problems are found.
+package main
+
+import (
+	"os"
+
+	"github.com/bradbev/flatland/examples/fruitroids/src/fruitroids"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/bradbev/flatland/src/flatcmd"
+)
+
+func main() {
+	flat.RegisterAllFlatTypes()
+	fruitroids.RegisterFruitroidTypes()
+	os.Exit(flatcmd.Check(os.Args[1:]))
+}
@@ -0,0 +1,18 @@
+// flatland-cook converts the fruitroids content to the binary asset format
+// in the cooked directory.  Run it from examples/fruitroids, then build with
+// -tags cooked to embed the cooked content.
+package main
+
+import (
+	"os"
+
+	"github.com/bradbev/flatland/examples/fruitroids/src/fruitroids"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/bradbev/flatland/src/flatcmd"
+)
+
+func main() {
+	flat.RegisterAllFlatTypes()
+	fruitroids.RegisterFruitroidTypes()
+	os.Exit(flatcmd.Cook(os.Args[1:]))
+}
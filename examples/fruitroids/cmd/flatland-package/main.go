@@ -0,0 +1,23 @@
+// flatland-package writes the fruitroids content that the game can reach
+// from gameflow.json, and nothing else, to content.zip.  Run it from
+// examples/fruitroids, then build with -tags packaged to embed the
+// archive.  It exits with 1 if any referenced file is missing.
+package main
+
+import (
+	"os"
+
+	"github.com/bradbev/flatland/examples/fruitroids/src/fruitroids"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/bradbev/flatland/src/flatcmd"
+)
+
+func main() {
+	flat.RegisterAllFlatTypes()
+	fruitroids.RegisterFruitroidTypes()
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"-out", "content.zip", "gameflow.json"}
+	}
+	os.Exit(flatcmd.Package(args))
+}
@@ -0,0 +1,17 @@
+// flatland-upgrade rewrites the fruitroids content at the current asset versions.
+// Run it from examples/fruitroids after adding an asset.RegisterMigration.
+package main
+
+import (
+	"os"
+
+	"github.com/bradbev/flatland/examples/fruitroids/src/fruitroids"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/bradbev/flatland/src/flatcmd"
+)
+
+func main() {
+	flat.RegisterAllFlatTypes()
+	fruitroids.RegisterFruitroidTypes()
+	os.Exit(flatcmd.Upgrade(os.Args[1:]))
+}
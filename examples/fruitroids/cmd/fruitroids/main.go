@@ -1,8 +1,6 @@
 package main
 
 import (
-	"io/fs"
-
 	content "github.com/bradbev/flatland/examples/fruitroids"
 	"github.com/bradbev/flatland/examples/fruitroids/src/fruitroids"
 	"github.com/bradbev/flatland/src/asset"
@@ -19,8 +17,9 @@ func main() {
 	// Create an embed filesystem and pass it to asset.  All content will
 	// come from here.  Any fs.FS can be given to asset.
 	// embed content to the binary so wasm distribution works
-	fsysRead, _ := fs.Sub(content.Content, "content")
-	asset.RegisterFileSystem(fsysRead, 0)
+	// Building with -tags cooked embeds the binary assets from `make cook`,
+	// and -tags packaged embeds only the reachable assets from `make package`
+	asset.RegisterFileSystem(content.FS(), 0)
 
 	// TOUR:Fruitroids 2
 	// Register the struct and interface types that the game uses.
@@ -1,6 +1,20 @@
+//go:build !cooked && !packaged
+
 package content
 
-import "embed"
+import (
+	"embed"
+	"io/fs"
+)
+
+// Root is the directory inside Content that holds the assets
+const Root = "content"
 
 //go:embed content
 var Content embed.FS
+
+// FS returns the file system that holds the assets
+func FS() fs.FS {
+	fsys, _ := fs.Sub(Content, Root)
+	return fsys
+}
@@ -0,0 +1,22 @@
+//go:build cooked && !packaged
+
+package content
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Root is the directory inside Content that holds the assets.
+// Build with -tags cooked after running `make cook` to ship the
+// binary assets.
+const Root = "cooked"
+
+//go:embed cooked
+var Content embed.FS
+
+// FS returns the file system that holds the assets
+func FS() fs.FS {
+	fsys, _ := fs.Sub(Content, Root)
+	return fsys
+}
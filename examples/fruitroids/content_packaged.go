@@ -0,0 +1,26 @@
+//go:build packaged
+
+package content
+
+import (
+	"bytes"
+	_ "embed"
+	"io/fs"
+
+	"github.com/bradbev/flatland/src/asset"
+)
+
+// archive holds only the content that the game can reach.  Build with
+// -tags packaged after running `make package`.
+//
+//go:embed content.zip
+var archive []byte
+
+// FS returns the file system that holds the assets
+func FS() fs.FS {
+	fsys, err := asset.OpenArchive(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
@@ -17,7 +17,6 @@ type Bullet struct {
 
 func (b *Bullet) BeginPlay() {
 	b.ActorBase.BeginPlay(b)
-	b.velocity = vector3.Vector3{}
 }
 
 func (b *Bullet) SetDirection(angle float64) {
@@ -33,14 +32,27 @@ func (b *Bullet) Update() {
 	v := b.velocity.MulScalar(deltaseconds)
 	b.Transform.Location = *b.Transform.Location.Add(v)
 	b.Transform.Rotation += b.RotationRate * deltaseconds
+	flat.TransformChanged(b)
 }
 
-func (b *Bullet) Draw(screen *ebiten.Image) {
+// BeginOverlap implements flat.OverlapHandler, a bullet destroys the first
+// roid it hits
+func (b *Bullet) BeginOverlap(self, other flat.Collider) {
+	if roid, ok := flat.OwningActor(other).(*Roid); ok {
+		getMainGame().IncScore(1)
+		ActiveWorld.Destroy(roid)
+		ActiveWorld.Destroy(b)
+	}
+}
+
+func (b *Bullet) EndOverlap(self, other flat.Collider) {}
+
+func (b *Bullet) Draw(ctx *flat.DrawContext) {
 	pos := b.Transform.Location
-	bounds := screen.Bounds()
+	bounds := ctx.Screen.Bounds()
 	if pos.X < 0 || pos.Y < 0 || pos.Y > float64(bounds.Max.Y) || pos.X > float64(bounds.Max.X) {
-		ActiveWorld.RemoveFromWorld(b)
+		ActiveWorld.Destroy(b)
 	}
 
-	b.ActorBase.Draw(screen)
+	b.ActorBase.Draw(ctx)
 }
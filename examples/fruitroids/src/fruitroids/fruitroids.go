@@ -94,23 +94,45 @@ func (g *GameFlow) NextWorld() {
 	g.activeWorld.BeginPlay()
 
 	if f := getMainGame(); f != nil {
-		f.scoreComponent = nil
+		f.findScoreComponent()
 		if f.gameFlow.index == WorldType_Main {
-			flat.ForEachActorByType[*flat.EmptyActor](g.activeWorld, func(actor *flat.EmptyActor) error {
-				for _, textComponent := range flat.FindComponentsByType[*flat.TextComponent](actor) {
-					if textComponent.Name == "Score" {
-						f.scoreComponent = textComponent
-						return flat.StopIterating
-					}
-				}
-				return nil
-			})
 			f.score = 0
 			f.IncScore(0)
 		}
 	}
 }
 
+func (f *Fruitroids) findScoreComponent() {
+	g := f.gameFlow
+	f.scoreComponent = nil
+	if g.index != WorldType_Main {
+		return
+	}
+	flat.ForEachActorByType[*flat.EmptyActor](g.activeWorld, func(actor *flat.EmptyActor) error {
+		for _, textComponent := range flat.FindComponentsByType[*flat.TextComponent](actor) {
+			if textComponent.Name == "Score" {
+				f.scoreComponent = textComponent
+				return flat.StopIterating
+			}
+		}
+		return nil
+	})
+}
+
+// Reloaded passes the assets that the editor reloaded during PIE on to the
+// active world.  The world may have begun play again, so the score is
+// shown on its new score component.
+func (f *Fruitroids) Reloaded(assets *asset.Manager, paths []asset.Path) error {
+	g := f.gameFlow
+	if g == nil || g.activeWorld == nil {
+		return nil
+	}
+	err := g.activeWorld.Reloaded(assets, paths)
+	f.findScoreComponent()
+	f.IncScore(0)
+	return err
+}
+
 var ActiveWorld *flat.World
 
 func (g *Fruitroids) Draw(screen *ebiten.Image) {
@@ -139,8 +161,6 @@ func RegisterFruitroidTypes() {
 	asset.RegisterAsset(Roid{})
 	asset.RegisterAsset(SpawnConfig{})
 	asset.RegisterAsset(LevelSpawn{})
-	asset.RegisterAsset(CircleCollisionComponent{})
-	asset.RegisterAsset(PhysicsCollisionManager{})
 	asset.RegisterAsset(Bullet{})
 	asset.RegisterAsset(GameFlow{})
 }
@@ -3,7 +3,6 @@ package fruitroids
 import (
 	"github.com/bradbev/flatland/src/flat"
 	"github.com/deeean/go-vector/vector3"
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type Roid struct {
@@ -16,6 +15,14 @@ func (r *Roid) BeginPlay() {
 	r.ActorBase.BeginPlay(r)
 }
 
+// Reinstanced keeps the roid moving when roid.json is reloaded
+func (r *Roid) Reinstanced(old flat.Actor) {
+	if old, ok := old.(*Roid); ok {
+		r.velocity = old.velocity
+		r.rotationDelta = old.rotationDelta
+	}
+}
+
 func (r *Roid) Update() {
 	r.ActorBase.Update()
 	r.updatePhysics()
@@ -26,11 +33,12 @@ func (r *Roid) updatePhysics() {
 	v := r.velocity.MulScalar(flat.FrameTime())
 	r.Transform.Location = *r.Transform.Location.Add(v)
 	r.Transform.AddRotation(r.rotationDelta)
+	flat.TransformChanged(r)
 }
 
-func (r *Roid) Draw(screen *ebiten.Image) {
+func (r *Roid) Draw(ctx *flat.DrawContext) {
 	pos := r.Transform.Location
-	b := screen.Bounds()
+	b := ctx.Screen.Bounds()
 	v := &r.velocity
 	if (pos.X < 0 && v.X < 0) || (pos.X > float64(b.Max.X) && v.X > 0) {
 		v.X *= -1
@@ -39,7 +47,7 @@ func (r *Roid) Draw(screen *ebiten.Image) {
 		v.Y *= -1
 	}
 
-	r.ActorBase.Draw(screen)
+	r.ActorBase.Draw(ctx)
 	//x, y := r.Transform.Location.X, r.Transform.Location.Y
 	//ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Roid at %v", r.Transform), int(x), int(y))
 }
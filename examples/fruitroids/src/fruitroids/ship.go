@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/bradbev/flatland/src/asset"
 	"github.com/bradbev/flatland/src/flat"
 	"github.com/deeean/go-vector/vector3"
 
@@ -18,8 +17,9 @@ import (
 // 1) The flat.Actor interface is satisfied by Ship
 // 2) Ship.BeginPlay *must* call ActorBase.BeginPlay(actor)
 // Actors can have sub components added to them.  The ship3.json
-// asset has an Image component and a CircleCollisionComponent, those
+// asset has an Image component and a flat.CircleCollider, those
 // components take care of showing an image and colliding into things.
+// The world tells the ship about collisions with BeginOverlap.
 // The exported fields of this struct will be editable in the FruitEditor.
 type Ship struct {
 	flat.ActorBase
@@ -34,6 +34,7 @@ type Ship struct {
 
 func (s *Ship) BeginPlay() {
 	s.Transform.Location = vector3.Vector3{}
+	flat.TransformChanged(s)
 	s.ActorBase.BeginPlay(s)
 	s.velocity = vector3.Vector3{}
 
@@ -76,28 +77,37 @@ func (s *Ship) handleInput() {
 			return
 		}
 		s.lastFireTime = time.Now()
-		// fire
-		b, err := asset.NewInstance(s.BulletType)
+		// fire, the bullet joins the world at the end of this update
+		b, err := ActiveWorld.Spawn(s.BulletType, s.Transform)
 		if err != nil {
 			fmt.Println("terrible")
+			return
 		}
-		bullet := b.(*Bullet)
-		bullet.Transform = s.Transform
-		ActiveWorld.AddToWorld(bullet)
-		bullet.SetDirection(s.Transform.Rotation)
+		b.(*Bullet).SetDirection(s.Transform.Rotation)
 	}
 }
 
 func (s *Ship) updatePhysics() {
-	// move along our velocity
+	// move along our velocity, handleInput may have turned the ship too
 	deltaseconds := flat.FrameTime()
 	v := s.velocity.MulScalar(deltaseconds)
 	s.Transform.Location = *s.Transform.Location.Add(v)
+	flat.TransformChanged(s)
+}
+
+// BeginOverlap implements flat.OverlapHandler, hitting a roid ends the level
+func (s *Ship) BeginOverlap(self, other flat.Collider) {
+	if _, ok := flat.OwningActor(other).(*Roid); ok {
+		s.velocity = vector3.Vector3{}
+		getMainGame().NextWorld()
+	}
 }
 
-func (s *Ship) Draw(screen *ebiten.Image) {
+func (s *Ship) EndOverlap(self, other flat.Collider) {}
+
+func (s *Ship) Draw(ctx *flat.DrawContext) {
 	pos := s.Transform.Location
-	b := screen.Bounds()
+	b := ctx.Screen.Bounds()
 	v := &s.velocity
 	if (pos.X < 0 && v.X < 0) || (pos.X > float64(b.Max.X) && v.X > 0) {
 		v.X *= -1
@@ -106,7 +116,7 @@ func (s *Ship) Draw(screen *ebiten.Image) {
 		v.Y *= -1
 	}
 
-	s.ActorBase.Draw(screen)
+	s.ActorBase.Draw(ctx)
 	//x, y := s.Transform.Location.X, s.Transform.Location.Y
 	//	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Ship at %.2f %v %v", s.velocity.Magnitude(), s.velocity, s.Transform.Rotation), int(x), int(y))
 }
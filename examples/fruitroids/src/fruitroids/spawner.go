@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math/rand"
 
-	"github.com/bradbev/flatland/src/asset"
 	"github.com/bradbev/flatland/src/flat"
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -30,21 +29,21 @@ func (l *LevelSpawn) BeginPlay() {
 	for _, toSpawn := range l.ToSpawn {
 		rng := toSpawn.CountMax - toSpawn.CountMin
 		for i := 0; i < rng+toSpawn.CountMin; i++ {
-			a, err := asset.NewInstance(toSpawn.RoidType)
+			w, h := ebiten.WindowSize()
+			transform := toSpawn.RoidType.Transform
+			transform.Location.X = float64(rand.Intn(w))
+			transform.Location.Y = float64(rand.Intn(h))
+			// spawned so that the roid is spawned again when roid.json is
+			// edited during PIE
+			a, err := ActiveWorld.Spawn(toSpawn.RoidType, transform)
 			if err != nil {
 				fmt.Println("terrible")
+				continue
 			}
 			r := a.(*Roid)
-			w, h := ebiten.WindowSize()
-			x, y := rand.Intn(w), rand.Intn(h)
-			r.Transform.Location.X = float64(x)
-			r.Transform.Location.Y = float64(y)
-
 			r.velocity.X = rand.Float64() * toSpawn.SpeedMax
 			r.velocity.Y = rand.Float64() * toSpawn.SpeedMax
 			r.rotationDelta = rand.Float64()*toSpawn.RotationMax - (toSpawn.RotationMax / 2.0)
-
-			ActiveWorld.AddToWorld(r)
 		}
 	}
 }
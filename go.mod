@@ -6,6 +6,7 @@ require (
 	github.com/hajimehoshi/ebiten/v2 v2.5.9
 	github.com/inkyblackness/imgui-go/v4 v4.7.0
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/image v0.11.0
 )
 
 require (
@@ -14,7 +15,6 @@ require (
 	github.com/jezek/xgb v1.1.0 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	golang.org/x/exp/shiny v0.0.0-20230817173708-d852ddb80c63 // indirect
-	golang.org/x/image v0.11.0 // indirect
 	golang.org/x/mobile v0.0.0-20230818142238-7088062f872d // indirect
 	golang.org/x/sync v0.3.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
@@ -0,0 +1,197 @@
+package asset
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveFS is a read only fs.FS over a zip archive, such as one written by
+// PackageArchive.  The archive is indexed when it is opened so that files
+// and directories are found without scanning it.  Register archives with a
+// lower priority number than the content they patch, see
+// RegisterFileSystem, to ship patches and mods as overlays.
+type ArchiveFS struct {
+	files  map[string]*zip.File
+	dirs   map[string][]fs.DirEntry
+	closer io.Closer
+}
+
+var _ fs.ReadFileFS = (*ArchiveFS)(nil)
+var _ fs.ReadDirFS = (*ArchiveFS)(nil)
+var _ fs.StatFS = (*ArchiveFS)(nil)
+
+// OpenArchive indexes the zip archive in r, which is size bytes long
+func OpenArchive(r io.ReaderAt, size int64) (*ArchiveFS, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	a := &ArchiveFS{
+		files: map[string]*zip.File{},
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+	for _, f := range reader.File {
+		name := strings.TrimSuffix(f.Name, "/")
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			a.addDir(name)
+			continue
+		}
+		a.files[name] = f
+		a.addEntry(name, fs.FileInfoToDirEntry(f.FileInfo()))
+	}
+	for _, entries := range a.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+	return a, nil
+}
+
+// OpenArchiveFile opens the zip archive at filename.  Close the ArchiveFS
+// when it is no longer registered.
+func OpenArchiveFile(filename string) (*ArchiveFS, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a, err := OpenArchive(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a.closer = f
+	return a, nil
+}
+
+// Close closes the archive file, if it was opened by OpenArchiveFile
+func (a *ArchiveFS) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// addDir makes sure that the directory name, and its parents, exist
+func (a *ArchiveFS) addDir(name string) {
+	if _, exists := a.dirs[name]; exists {
+		return
+	}
+	a.dirs[name] = nil
+	a.addEntry(name, fs.FileInfoToDirEntry(archiveDirInfo(path.Base(name))))
+}
+
+// addEntry lists the file or directory name in its parent directory
+func (a *ArchiveFS) addEntry(name string, entry fs.DirEntry) {
+	parent := path.Dir(name)
+	a.addDir(parent)
+	a.dirs[parent] = append(a.dirs[parent], entry)
+}
+
+func (a *ArchiveFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if f, ok := a.files[name]; ok {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		return &archiveFile{ReadCloser: rc, info: f.FileInfo()}, nil
+	}
+	if entries, ok := a.dirs[name]; ok {
+		return &archiveDir{name: name, entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (a *ArchiveFS) ReadFile(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data := make([]byte, f.UncompressedSize64)
+	if _, err := io.ReadFull(rc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (a *ArchiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := a.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+func (a *ArchiveFS) Stat(name string) (fs.FileInfo, error) {
+	if f, ok := a.files[name]; ok {
+		return f.FileInfo(), nil
+	}
+	if _, ok := a.dirs[name]; ok {
+		return archiveDirInfo(path.Base(name)), nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+type archiveFile struct {
+	io.ReadCloser
+	info fs.FileInfo
+}
+
+func (f *archiveFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+type archiveDir struct {
+	name    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *archiveDir) Stat() (fs.FileInfo, error) { return archiveDirInfo(path.Base(d.name)), nil }
+func (d *archiveDir) Close() error               { return nil }
+func (d *archiveDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *archiveDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), remaining...), nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), remaining[:n]...), nil
+}
+
+// archiveDirInfo describes a directory, archives do not have to store them
+type archiveDirInfo string
+
+func (d archiveDirInfo) Name() string       { return string(d) }
+func (d archiveDirInfo) Size() int64        { return 0 }
+func (d archiveDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (d archiveDirInfo) ModTime() time.Time { return time.Time{} }
+func (d archiveDirInfo) IsDir() bool        { return true }
+func (d archiveDirInfo) Sys() any           { return nil }
@@ -0,0 +1,109 @@
+package asset_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeArchive(t *testing.T, files map[string]string) *asset.ArchiveFS {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, contents := range files {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		f.Write([]byte(contents))
+	}
+	assert.NoError(t, w.Close())
+	archive, err := asset.OpenArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return archive
+}
+
+func TestArchiveFS(t *testing.T) {
+	archive := writeArchive(t, map[string]string{
+		"a.json":          "a",
+		"raw/b.png":       "b",
+		"raw/deep/c.png":  "c",
+		"empty/":          "",
+		"other/d.json":    "d",
+		"../escaped.json": "skipped",
+	})
+	assert.NoError(t, fstest.TestFS(archive, "a.json", "raw/b.png", "raw/deep/c.png", "other/d.json", "empty"))
+
+	data, err := fs.ReadFile(archive, "raw/deep/c.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", string(data))
+	_, err = archive.ReadFile("escaped.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestArchiveOverlay(t *testing.T) {
+	newRoidManager := func(wfs *writeFS, priority int) *asset.Manager {
+		m := asset.NewManager()
+		m.RegisterAsset(roid{})
+		m.RegisterFileSystem(wfs.fs, priority)
+		m.RegisterWritableFileSystem(wfs)
+		return m
+	}
+	wfs := newWriteFS()
+	content := newRoidManager(wfs, 10)
+	assert.NoError(t, content.Save("small.json", &roid{Size: 1, Colour: "red"}))
+	assert.NoError(t, content.Save("big.json", &roid{Size: 10, Colour: "red"}))
+
+	patched := &bytes.Buffer{}
+	patcher := newRoidManager(newWriteFS(), 0)
+	assert.NoError(t, patcher.Save("big.json", &roid{Size: 20, Colour: "blue"}))
+	assert.NoError(t, patcher.Save("mod/huge.json", &roid{Size: 100}))
+	_, err := patcher.PackageArchive(patched, "big.json", "mod/huge.json")
+	assert.NoError(t, err)
+	patch, err := asset.OpenArchive(bytes.NewReader(patched.Bytes()), int64(patched.Len()))
+	assert.NoError(t, err)
+
+	// the patch has a lower priority number, so it is searched first
+	m := newRoidManager(wfs, 10)
+	m.RegisterFileSystem(patch, 0)
+
+	big, err := m.Load("big.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &roid{Size: 20, Colour: "blue"}, big)
+	assert.Equal(t, patch, m.SourceFileSystem(big))
+	small, err := m.Load("small.json")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, small.(*roid).Size)
+	assert.IsType(t, fstest.MapFS{}, m.SourceFileSystem(small))
+
+	// shadowed files are only walked once
+	var files []string
+	assert.NoError(t, m.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"big.json", "mod/huge.json", "small.json"}, files)
+
+	// skipping a directory skips it in every file system
+	assert.NoError(t, content.Save("mod/local.json", &roid{Size: 5}))
+	files = nil
+	assert.NoError(t, m.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if d.IsDir() && path == "mod" {
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"big.json", "small.json"}, files)
+
+	roids, err := m.FilterFilesByType(reflect.TypeOf(roid{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"big.json", "mod/huge.json", "mod/local.json", "small.json"}, roids)
+}
@@ -17,7 +17,7 @@
 package asset
 
 import (
-	"fmt"
+	"io"
 	"io/fs"
 	systemLog "log"
 	"os"
@@ -52,6 +52,20 @@ type PostLoadingAsset interface {
 	PostLoad()
 }
 
+// Unloadable is the counterpart of PostLoadingAsset.  Unload is called
+// when the asset is unloaded so that it can release what PostLoad created,
+// such as textures.  It is also called for the inline values that the
+// asset owns, but not for the assets it refers to.
+type Unloadable interface {
+	Unload()
+}
+
+// SizedAsset reports the memory an asset holds outside of its Go values,
+// such as a texture, so that Stats can include it
+type SizedAsset interface {
+	ApproximateSize() int64
+}
+
 type PreSavingAsset interface {
 	PreSave()
 }
@@ -69,39 +83,136 @@ type DefaultInitializer interface {
 	DefaultInitialize()
 }
 
+// ManagerAwareAsset is implemented by assets that need to know which
+// Manager created them, for example to read raw files from the same
+// file systems in PostLoad.  SetAssetManager is called before PostLoad.
+type ManagerAwareAsset interface {
+	SetAssetManager(m *Manager)
+}
+
 type FactoryFunc func() (Asset, error)
 
+// RegisterFileSystem adds a file system that assets are read from.  File
+// systems with a lower priority number are searched first, so a patch or
+// mod registered with a lower number than the game content overrides the
+// files it contains.
 func RegisterFileSystem(filesystem fs.FS, priority int) error {
-	wrapped := &fsWrapper{FileSystem: filesystem, Priority: priority}
-	return assetManager.AddFS(wrapped)
+	return defaultManager.RegisterFileSystem(filesystem, priority)
 }
 
 func RegisterWritableFileSystem(filesystem WriteableFileSystem) error {
-	assetManager.WriteFS = filesystem
-	return nil
+	return defaultManager.RegisterWritableFileSystem(filesystem)
 }
 
 // SetEditorMode informs the asset package that it is being used in an
 // editor context.  For example when enabled extra meta data about
 // which fields a child asset overrides must be saved.
 func SetEditorMode() {
-	assetManager.EditorMode = true
+	defaultManager.SetEditorMode()
+}
+
+// SetOmitDefaults makes Save leave out the fields of an asset that have the
+// default value of its type (see DefaultInitializer), rather than only the
+// fields with the zero value.  Assets with a parent save what differs from
+// the parent either way.
+func SetOmitDefaults(omit bool) {
+	defaultManager.SetOmitDefaults(omit)
 }
 
 func RegisterAssetFactory(zeroAsset any, factoryFunction FactoryFunc) {
-	assetManager.RegisterAssetFactory(zeroAsset, factoryFunction)
+	defaultManager.RegisterAssetFactory(zeroAsset, factoryFunction)
 }
 
 func RegisterAsset(zeroAsset any) {
-	assetManager.RegisterAssetFactory(zeroAsset, func() (Asset, error) {
-		zeroType := reflect.TypeOf(zeroAsset)
-		zero := reflect.New(zeroType)
-		return zero.Interface().(Asset), nil
-	})
+	defaultManager.RegisterAsset(zeroAsset)
+}
+
+// RegisterMigration registers a migration with the DefaultManager.  See
+// Manager.RegisterMigration.
+func RegisterMigration(zeroAsset any, fromVersion int, migrate MigrationFunc) {
+	defaultManager.RegisterMigration(zeroAsset, fromVersion, migrate)
+}
+
+// Upgrade rewrites every out of date asset at its current version.  See
+// Manager.Upgrade.
+func Upgrade() ([]Path, error) {
+	return defaultManager.Upgrade()
+}
+
+// StampIDs gives the assets that do not have an ID one, see
+// Manager.StampIDs
+func StampIDs() ([]Path, error) {
+	return defaultManager.StampIDs()
+}
+
+// Resave saves every asset again, so that it is canonical.  See
+// Manager.Resave.
+func Resave() ([]Path, error) {
+	return defaultManager.Resave()
+}
+
+// AssetID returns the stable ID of a loaded or saved asset
+func AssetID(asset Asset) string {
+	return defaultManager.AssetID(asset)
+}
+
+// Move renames a file and fixes up every asset that refers to it.  See
+// Manager.Move.
+func Move(oldPath, newPath Path) error {
+	return defaultManager.Move(oldPath, newPath)
+}
+
+// Delete removes a file.  See Manager.Delete.
+func Delete(path Path) error {
+	return defaultManager.Delete(path)
+}
+
+// Check returns the problems in the DefaultManager's assets.  See
+// Manager.Check.
+func Check() []Problem {
+	return defaultManager.Check()
+}
+
+// NewWatcher creates a Watcher for the DefaultManager.  See
+// Manager.NewWatcher.
+func NewWatcher() *Watcher {
+	return defaultManager.NewWatcher()
+}
+
+// Dependencies returns the files that the asset at path refers to
+func Dependencies(path Path) []Path {
+	return defaultManager.Dependencies(path)
+}
+
+// Referencers returns the asset files that refer to path
+func Referencers(path Path) []Path {
+	return defaultManager.Referencers(path)
 }
 
 func ReadFile(assetPath Path) ([]byte, error) {
-	return assetManager.ReadFile(assetPath)
+	return defaultManager.ReadFile(assetPath)
+}
+
+// SourceFileSystem returns the registered file system that a loaded asset
+// was read from
+func SourceFileSystem(asset Asset) fs.FS {
+	return defaultManager.SourceFileSystem(asset)
+}
+
+// Unload forgets the loaded asset at path, see Manager.Unload
+func Unload(path Path) error {
+	return defaultManager.Unload(path)
+}
+
+// Acquire loads the asset at path and counts a reference to it, see
+// Manager.Acquire
+func Acquire(path Path) (*Handle, error) {
+	return defaultManager.Acquire(path)
+}
+
+// LoadedStats reports the loaded assets and their approximate size
+func LoadedStats() Stats {
+	return defaultManager.Stats()
 }
 
 type LoadOptions struct {
@@ -116,19 +227,49 @@ type LoadOptions struct {
 }
 
 func LoadWithOptions(assetPath Path, options LoadOptions) (Asset, error) {
-	return assetManager.LoadWithOptions(assetPath, options)
+	return defaultManager.LoadWithOptions(assetPath, options)
 }
 
 func Load(assetPath Path) (Asset, error) {
-	return assetManager.Load(assetPath)
+	return defaultManager.Load(assetPath)
+}
+
+// LoadAsync loads assetPath without blocking the caller.  The Result is
+// delivered on the returned channel once loading completes.
+func LoadAsync(assetPath Path) <-chan Result {
+	return defaultManager.LoadAsync(assetPath)
 }
 
 func NewInstance(a Asset) (Asset, error) {
-	return assetManager.NewInstance(a)
+	return defaultManager.NewInstance(a)
 }
 
 func Save(path Path, toSave Asset) error {
-	return assetManager.Save(path, toSave)
+	return defaultManager.Save(path, toSave)
+}
+
+// Cook converts all the content readable by asset to the binary format and
+// writes it to dest.  See Manager.Cook.
+func Cook(dest WriteableFileSystem) error {
+	return defaultManager.Cook(dest)
+}
+
+// Reachable returns the files that the assets at roots need.  See
+// Manager.Reachable.
+func Reachable(roots ...Path) ([]Path, error) {
+	return defaultManager.Reachable(roots...)
+}
+
+// Package writes the files that the assets at roots need to dest.  See
+// Manager.Package.
+func Package(dest WriteableFileSystem, roots ...Path) ([]Path, error) {
+	return defaultManager.Package(dest, roots...)
+}
+
+// PackageArchive writes the files that the assets at roots need to w as a
+// zip archive.  See Manager.PackageArchive.
+func PackageArchive(w io.Writer, roots ...Path) ([]Path, error) {
+	return defaultManager.PackageArchive(w, roots...)
 }
 
 // SetParent is used to set the parent of an Asset.
@@ -137,34 +278,63 @@ func Save(path Path, toSave Asset) error {
 // and the child are diffed and in places where they differ the child will override
 // the parent.
 func SetParent(child Asset, parent Asset) error {
-	return assetManager.SetParent(child, parent)
+	return defaultManager.SetParent(child, parent)
 }
 
 func GetParent(child Asset) Path {
-	return assetManager.GetParent(child)
+	return defaultManager.GetParent(child)
 }
 
 func GetParentAsset(child Asset) Asset {
-	parentPath := assetManager.GetParent(child)
-	if parentPath == "" {
-		return nil
-	}
-	parent, _ := assetManager.Load(parentPath)
-	return parent
+	return defaultManager.GetParentAsset(child)
+}
+
+// ParentChain returns the paths of child's parent, its parent's parent and
+// so on, nearest first
+func ParentChain(child Asset) []Path {
+	return defaultManager.ParentChain(child)
+}
+
+// ResolveFieldOrigin returns the asset in child's parent chain that
+// supplies the value of the field at fieldPath.  See
+// Manager.ResolveFieldOrigin.
+func ResolveFieldOrigin(child Asset, fieldPath string) Asset {
+	return defaultManager.ResolveFieldOrigin(child, fieldPath)
 }
 
 func GetLoadPathForAsset(a Asset) (Path, error) {
-	path, ok := assetManager.AssetToLoadPath[a]
-	if !ok {
-		return path, fmt.Errorf("Asset not loaded")
-	}
-	return path, nil
+	return defaultManager.GetLoadPathForAsset(a)
+}
+
+func SetChildOverrideForField(child Asset, pathToField string, enable OverrideEnableType) error {
+	return defaultManager.SetChildOverrideForField(child, pathToField, enable)
+}
+
+func ChildOverridesField(child Asset, pathToField string) bool {
+	return defaultManager.ChildOverridesField(child, pathToField)
+}
+
+// ChildOverridePaths returns the paths of the fields that child overrides
+func ChildOverridePaths(child Asset) []string {
+	return defaultManager.ChildOverridePaths(child)
+}
+
+// ApplyOverridesToParent moves the values child overrides into its parent.
+// See Manager.ApplyOverridesToParent.
+func ApplyOverridesToParent(child Asset, fieldPaths []string) error {
+	return defaultManager.ApplyOverridesToParent(child, fieldPaths)
+}
+
+// RevertOverrides makes child take its parent's values again.  See
+// Manager.RevertOverrides.
+func RevertOverrides(child Asset, fieldPaths []string) error {
+	return defaultManager.RevertOverrides(child, fieldPaths)
 }
 
 // WalkFiles is like fs.WalkDir, but it will walk all the readable file systems
 // registered with asset.RegisterFileSystem
 func WalkFiles(fn fs.WalkDirFunc) error {
-	return assetManager.WalkFiles(fn)
+	return defaultManager.WalkFiles(fn)
 }
 
 func FilterFilesByType[T any]() ([]string, error) {
@@ -173,7 +343,7 @@ func FilterFilesByType[T any]() ([]string, error) {
 }
 
 func FilterFilesByReflectType(typ reflect.Type) ([]string, error) {
-	return assetManager.FilterFilesByType(typ)
+	return defaultManager.FilterFilesByType(typ)
 }
 
 func FilterAssetDescriptorsByType[T any]() []*AssetDescriptor {
@@ -182,21 +352,22 @@ func FilterAssetDescriptorsByType[T any]() []*AssetDescriptor {
 }
 
 func FilterAssetDescriptorsByReflectType(typ reflect.Type) []*AssetDescriptor {
-	return assetManager.FilterAssetDescriptorsByType(typ)
+	return defaultManager.FilterAssetDescriptorsByType(typ)
 }
 
+// ResetForTest replaces the DefaultManager with a new, empty, Manager
+// that is in editor mode.
 func ResetForTest() {
-	assetManager = newAssetManagerImpl()
-	assetManager.EditorMode = true
+	defaultManager = NewManager()
+	defaultManager.EditorMode = true
 }
 
 func GetAssetDescriptors() []*AssetDescriptor {
-	return assetManager.AssetDescriptorList
+	return defaultManager.GetAssetDescriptors()
 }
 
 func GetDescriptorForAsset(asset Asset) *AssetDescriptor {
-	_, typeName := ObjectTypeName(asset)
-	return assetManager.AssetDescriptors[typeName]
+	return defaultManager.GetAssetDescriptor(asset)
 }
 
 func ObjectTypeName(obj any) (name string, fullname string) {
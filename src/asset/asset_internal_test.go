@@ -1,13 +1,17 @@
 package asset
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"reflect"
 	"testing"
+	"testing/fstest"
 
-	"github.com/psanford/memfs"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
 )
 
 type testLeaf struct{ Leaf string }
@@ -39,12 +43,12 @@ func TestBuildJsonToSave(t *testing.T) {
 		SliceOfIface: []any{&leaf},
 	}
 
-	a := assetManagerImpl{
+	a := Manager{
 		AssetToLoadPath: map[Asset]Path{},
 	}
 	a.AssetToLoadPath[&leaf] = "fullPath.json"
 	m := a.toCommonFormat(node)
-	diffsFromParent := findDiffsFromParentCommonFormat(nil, m)
+	diffsFromParent := a.findDiffsFromParentCommonFormat(nil, m, reflect.TypeOf(node), nil)
 
 	j, err := json.MarshalIndent(diffsFromParent, "", "")
 	assert.NoError(t, err)
@@ -102,7 +106,7 @@ func TestUnmashallFromAny(t *testing.T) {
 	err := json.Unmarshal([]byte(jsToUse), &toUnmarshal)
 	assert.NoError(t, err)
 
-	a := assetManagerImpl{
+	a := Manager{
 		AssetToLoadPath: map[Asset]Path{},
 		LoadPathToAsset: map[Path]Asset{},
 	}
@@ -121,7 +125,7 @@ func TestUnmashallFromAny(t *testing.T) {
 			}
 		}()
 
-		a.unmarshalCommonFormat(toUnmarshal, &node)
+		a.unmarshalCommonFormat(toUnmarshal, &node, false, loadChain{})
 
 	}()
 
@@ -156,11 +160,12 @@ type parent struct {
 type jsmap = map[string]any
 
 func TestFindDiffsFromParent(t *testing.T) {
-	assetman := &assetManagerImpl{}
+	assetman := &Manager{}
 	fd := func(a, b any) any {
-		r := findDiffsFromParentCommonFormat(
+		r := assetman.findDiffsFromParentCommonFormat(
 			assetman.toCommonFormat(a),
-			assetman.toCommonFormat(b))
+			assetman.toCommonFormat(b),
+			reflect.TypeOf(b), nil)
 		//jsp("Return::::::", r)
 		return r
 	}
@@ -199,8 +204,18 @@ func TestFindDiffsFromParent(t *testing.T) {
 
 		p = parent{Slice: []int{1, 2}}
 		c = parent{Slice: []int{1, 3}}
-		expected := jsmap{"Slice": []any{1, 3}}
-		assert.Equal(t, expected, fd(p, c), "Differing slices need to be saved")
+		expected := jsmap{"Slice": jsmap{"Elements": jsmap{"1": 3}}}
+		assert.Equal(t, expected, fd(p, c), "Differing elements need to be saved")
+
+		p = parent{Slice: []int{1, 2, 3}}
+		c = parent{Slice: []int{1}}
+		expected = jsmap{"Slice": jsmap{"Removed": []string{"1", "2"}}}
+		assert.Equal(t, expected, fd(p, c), "Removed elements need to be saved")
+
+		p = parent{Slice: []int{1}}
+		c = parent{Slice: []int{1, 2}}
+		expected = jsmap{"Slice": jsmap{"Elements": jsmap{"+0": 2}}}
+		assert.Equal(t, expected, fd(p, c), "Added elements need to be saved, with ids the parent's indexes can't take")
 	}
 }
 
@@ -253,17 +268,36 @@ func TestGetFlatTag(t *testing.T) {
 }
 
 type writeFS struct {
-	fs *memfs.FS
+	fs fstest.MapFS
 }
 
 func newWriteFS() *writeFS {
 	return &writeFS{
-		fs: memfs.New(),
+		fs: fstest.MapFS{},
 	}
 }
 
 func (f *writeFS) WriteFile(path Path, data []byte) error {
-	return f.fs.WriteFile(string(path), data, 0777)
+	f.fs[string(path)] = &fstest.MapFile{Data: bytes.Clone(data), Mode: 0777}
+	return nil
+}
+
+func (f *writeFS) Rename(oldPath, newPath Path) error {
+	file, ok := f.fs[string(oldPath)]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.fs, string(oldPath))
+	f.fs[string(newPath)] = file
+	return nil
+}
+
+func (f *writeFS) Remove(path Path) error {
+	if _, ok := f.fs[string(path)]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.fs, string(path))
+	return nil
 }
 
 type testAssetParent struct {
@@ -286,10 +320,10 @@ type testMemberPathCreations struct {
 }
 
 func TestCreatePathsFromCommonFormat(t *testing.T) {
-	c := assetManager.toCommonFormat(testMemberPathCreations{})
+	c := defaultManager.toCommonFormat(testMemberPathCreations{})
 
 	o := newChildOverrides()
-	o.BuildFromCommonFormat(c)
+	o.BuildFromCommonFormat(c, reflect.TypeOf(testMemberPathCreations{}))
 
 	expectedPaths := []string{
 		"First.Second",
@@ -327,7 +361,7 @@ func TestParentLoadingSavingSetting(t *testing.T) {
 			StrB: "ChildB",
 		})
 		SetParent(child.Children[0], parent)
-		overrides := assetManager.ChildAssetOverrides[child.Children[0]]
+		overrides := defaultManager.ChildAssetOverrides[child.Children[0]]
 		assert.NotNil(t, overrides)
 		assert.False(t, overrides.PathHasOverride("StrA"), "When a parent is set, any values that match the parent will be inherited")
 		assert.True(t, overrides.PathHasOverride("StrB"), "When a parent is set, any values that match the parent will be inherited")
@@ -344,7 +378,7 @@ func TestParentLoadingSavingSetting(t *testing.T) {
 		assert.NoError(t, err)
 		child := loadedChild.(*childContainer)
 
-		overrides := assetManager.ChildAssetOverrides[child.Children[0]]
+		overrides := defaultManager.ChildAssetOverrides[child.Children[0]]
 		assert.NotNil(t, overrides)
 		assert.False(t, overrides.PathHasOverride("StrA"), "When a parent is set, any values that match the parent will be inherited")
 		assert.True(t, overrides.PathHasOverride("StrB"), "When a parent is set, any values that match the parent will be inherited")
@@ -436,10 +470,23 @@ func TestInlineAssetsWithParents(t *testing.T) {
 		container := testAssetInlineParent{Inlined: inlinedChild}
 		SetParent(inlinedChild, &parent)
 		fmt.Printf("Child %x\n", &container)
-		for k, v := range assetManager.ChildAssetOverrides {
+		for k, v := range defaultManager.ChildAssetOverrides {
 			fmt.Printf("k %x v: %v\n", k, v)
 		}
 		assert.True(t, ChildOverridesField(inlinedChild, "Field"))
 
 	}
 }
+
+func TestDecodeBinaryCorruptCounts(t *testing.T) {
+	huge := binary.AppendUvarint(nil, 1<<40)
+	corrupt := map[string][]byte{
+		"string table": append(slices.Clone(binaryMagic), huge...),
+		"list":         append(append(slices.Clone(binaryMagic), 0, binaryTagList), huge...),
+		"map":          append(append(slices.Clone(binaryMagic), 0, binaryTagMap), huge...),
+	}
+	for name, data := range corrupt {
+		_, err := decodeBinary(data)
+		assert.Equal(t, errBinaryTruncated, err, name)
+	}
+}
@@ -1,11 +1,16 @@
 package asset_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/bradbev/flatland/src/asset"
 
@@ -69,17 +74,53 @@ func newTestAsset(name asset.Path, data string) {
 }
 
 type writeFS struct {
-	fs *memfs.FS
+	fs fstest.MapFS
+	// writes counts WriteFile calls, it is used as the ModTime so that
+	// every write is seen as a change
+	writes int64
 }
 
 func newWriteFS() *writeFS {
 	return &writeFS{
-		fs: memfs.New(),
+		fs: fstest.MapFS{},
 	}
 }
 
+// newTestManager creates an editor mode Manager for assets that reads and
+// writes wfs
+func newTestManager(wfs *writeFS, assets ...any) *asset.Manager {
+	m := asset.NewManager()
+	m.SetEditorMode()
+	for _, a := range assets {
+		m.RegisterAsset(a)
+	}
+	m.RegisterFileSystem(wfs.fs, 0)
+	m.RegisterWritableFileSystem(wfs)
+	return m
+}
+
 func (f *writeFS) WriteFile(path asset.Path, data []byte) error {
-	return f.fs.WriteFile(string(path), data, 0777)
+	f.writes++
+	f.fs[string(path)] = &fstest.MapFile{Data: bytes.Clone(data), Mode: 0777, ModTime: time.Unix(f.writes, 0)}
+	return nil
+}
+
+func (f *writeFS) Rename(oldPath, newPath asset.Path) error {
+	file, ok := f.fs[string(oldPath)]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.fs, string(oldPath))
+	f.fs[string(newPath)] = file
+	return nil
+}
+
+func (f *writeFS) Remove(path asset.Path) error {
+	if _, ok := f.fs[string(path)]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.fs, string(path))
+	return nil
 }
 
 type js = map[string]any
@@ -104,6 +145,7 @@ func TestAssetSave(t *testing.T) {
 
 	expected := js{
 		"Type":   "github.com/bradbev/flatland/src/asset_test.testAsset",
+		"ID":     asset.AssetID(a),
 		"Parent": "",
 		"Inner": js{
 			"Anykey": "saved",
@@ -191,32 +233,35 @@ func TestAssetSaveLinked(t *testing.T) {
 
 	bytesBack, err := fs.ReadFile(rootFS.fs, "node.json")
 	assert.NoError(t, err)
-	expected :=
+	expected := fmt.Sprintf(
 		`{
   "Type": "github.com/bradbev/flatland/src/asset_test.testAssetNode",
+  "ID": "%s",
   "Parent": "",
   "Inner": {
+    "Name": "node",
     "Inline": {
       "SecondName": "inline"
     },
-    "Name": "node",
     "Reference": {
       "Type": "github.com/bradbev/flatland/src/asset_test.testAssetLeaf",
-      "Path": "leaf.json"
+      "Path": "leaf.json",
+      "ID": "%s"
     }
   }
-}`
+}`, asset.AssetID(node), asset.AssetID(leaf))
 	assert.Equal(t, expected, string(bytesBack))
 
 	bytesBack, err = fs.ReadFile(rootFS.fs, "leaf.json")
-	leafExpected :=
+	leafExpected := fmt.Sprintf(
 		`{
   "Type": "github.com/bradbev/flatland/src/asset_test.testAssetLeaf",
+  "ID": "%s",
   "Parent": "",
   "Inner": {
     "SecondName": "Leaf"
   }
-}`
+}`, asset.AssetID(leaf))
 	assert.Equal(t, leafExpected, string(bytesBack))
 
 }
@@ -377,6 +422,7 @@ func TestInlineAssetSaving(t *testing.T) {
 
 	d, _ := asset.ReadFile("inlined.json")
 	fmt.Println(string(d))
+	savedID, parentID := asset.AssetID(toSave), asset.AssetID(parent)
 
 	reset()
 
@@ -389,13 +435,15 @@ func TestInlineAssetSaving(t *testing.T) {
 	}
 	assert.Equal(t, expected, loaded)
 
-	exactFileContent := `{
+	exactFileContent := fmt.Sprintf(`{
   "Type": "github.com/bradbev/flatland/src/asset_test.inlineSaving",
+  "ID": "%[1]s",
   "Parent": "",
   "Inner": {
     "SaveInline": {
       "Type": "github.com/bradbev/flatland/src/asset_test.inlineInnerSaveStruct",
       "Parent": "parent.json",
+      "ParentID": "%[2]s",
       "Inner": {
         "ItemA": "InlineA"
       }
@@ -403,6 +451,7 @@ func TestInlineAssetSaving(t *testing.T) {
     "SaveInlineNoChanges": {
       "Type": "github.com/bradbev/flatland/src/asset_test.inlineInnerSaveStruct",
       "Parent": "parent.json",
+      "ParentID": "%[2]s",
       "Inner": null
     },
     "SaveInterfaceInline": {
@@ -414,7 +463,7 @@ func TestInlineAssetSaving(t *testing.T) {
       }
     }
   }
-}`
+}`, savedID, parentID)
 	data, err := fs.ReadFile(rootFS.fs, "inlined.json")
 	assert.Equal(t, exactFileContent, string(data), "The inlined field has a parent etc")
 }
@@ -542,3 +591,270 @@ func TestAssetNewInstance(t *testing.T) {
 	assert.True(t, instB.postloaded, "PostLoad is called when Load is used")
 
 }
+
+type managerAwareTest struct {
+	Anykey  string
+	manager *asset.Manager
+}
+
+func (m *managerAwareTest) SetAssetManager(manager *asset.Manager) { m.manager = manager }
+
+func TestManagersAreIsolated(t *testing.T) {
+	asset.ResetForTest()
+	newManager := func(value string) *asset.Manager {
+		m := asset.NewManager()
+		m.RegisterAsset(managerAwareTest{})
+		rootFS := memfs.New()
+		rootFS.WriteFile("asset.json", []byte(fmt.Sprintf(`{
+	"Type": "github.com/bradbev/flatland/src/asset_test.managerAwareTest",
+	"Inner": {
+		"Anykey": "%s"
+	}
+}`, value)), 0777)
+		m.RegisterFileSystem(rootFS, 0)
+		return m
+	}
+	m1 := newManager("one")
+	m2 := newManager("two")
+
+	a1, err := m1.Load("asset.json")
+	assert.NoError(t, err)
+	a2, err := m2.Load("asset.json")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "one", a1.(*managerAwareTest).Anykey)
+	assert.Equal(t, "two", a2.(*managerAwareTest).Anykey)
+	assert.Equal(t, m1, a1.(*managerAwareTest).manager)
+	assert.Equal(t, m2, a2.(*managerAwareTest).manager)
+
+	path, err := m1.GetLoadPathForAsset(a1)
+	assert.NoError(t, err)
+	assert.Equal(t, asset.Path("asset.json"), path)
+	_, err = m2.GetLoadPathForAsset(a1)
+	assert.Error(t, err, "m2 must not know about assets loaded by m1")
+
+	// the default manager has neither the type nor the file system
+	_, err = asset.Load("asset.json")
+	assert.Error(t, err)
+}
+
+var slowLoadCount atomic.Int32
+
+type slowLoad struct {
+	Anykey string
+}
+
+func (s *slowLoad) PostLoad() {
+	slowLoadCount.Add(1)
+	// widen the window in which other goroutines can ask for the same asset
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestConcurrentLoad(t *testing.T) {
+	asset.ResetForTest()
+	asset.RegisterAsset(slowLoad{})
+	newTestAsset("slow.json", `{
+	"Type": "github.com/bradbev/flatland/src/asset_test.slowLoad",
+	"Inner": {
+		"Anykey": "hi"
+	}
+}`)
+	slowLoadCount.Store(0)
+
+	const loaders = 16
+	results := make([]asset.Asset, loaders)
+	var wg sync.WaitGroup
+	for i := 0; i < loaders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a, err := asset.Load("slow.json")
+			assert.NoError(t, err)
+			results[i] = a
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), slowLoadCount.Load(), "concurrent loads must share one load")
+	for _, a := range results {
+		assert.True(t, a == results[0], "all loads must return the same asset")
+	}
+
+	result := <-asset.LoadAsync("slow.json")
+	assert.NoError(t, result.Err)
+	assert.True(t, result.Asset == results[0])
+
+	result = <-asset.LoadAsync("missing.json")
+	assert.Error(t, result.Err)
+	assert.Nil(t, result.Asset)
+}
+
+func TestLoadCycle(t *testing.T) {
+	wfs := newWriteFS()
+	wfs.WriteFile("a.json", checkAsset("checkShip", "", `{"Target": `+ref("b.json")+`}`))
+	wfs.WriteFile("b.json", checkAsset("checkShip", "", `{"Target": `+ref("a.json")+`}`))
+	wfs.WriteFile("child.json", checkAsset("checkShip", "parent.json", `{}`))
+	wfs.WriteFile("parent.json", checkAsset("checkShip", "child.json", `{}`))
+	m := asset.NewManager()
+	m.RegisterAsset(checkShip{})
+	m.RegisterFileSystem(wfs.fs, 0)
+
+	load := func(path asset.Path) error {
+		select {
+		case result := <-m.LoadAsync(path):
+			return result.Err
+		case <-time.After(5 * time.Second):
+			t.Fatalf("loading %s did not finish", path)
+			return nil
+		}
+	}
+	err := load("a.json")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cyclic references a.json -> b.json -> a.json")
+	}
+	err = load("child.json")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cyclic references child.json -> parent.json -> child.json")
+	}
+}
+
+// barrierFS holds the first Open of each of its paths until all of them
+// have been opened, so that loads on different goroutines overlap
+type barrierFS struct {
+	fs.FS
+	mu     sync.Mutex
+	opened map[string]bool
+	all    sync.WaitGroup
+}
+
+func newBarrierFS(fsys fs.FS, paths ...string) *barrierFS {
+	b := &barrierFS{FS: fsys, opened: map[string]bool{}}
+	for _, path := range paths {
+		b.opened[path] = false
+	}
+	b.all.Add(len(paths))
+	return b
+}
+
+func (b *barrierFS) Open(name string) (fs.File, error) {
+	b.mu.Lock()
+	opened, held := b.opened[name]
+	b.opened[name] = true
+	b.mu.Unlock()
+	if held && !opened {
+		b.all.Done()
+		b.all.Wait()
+	}
+	return b.FS.Open(name)
+}
+
+func TestConcurrentLoadCycle(t *testing.T) {
+	wfs := newWriteFS()
+	wfs.WriteFile("a.json", checkAsset("checkShip", "", `{"Target": `+ref("b.json")+`}`))
+	wfs.WriteFile("b.json", checkAsset("checkShip", "", `{"Target": `+ref("a.json")+`}`))
+	m := asset.NewManager()
+	m.RegisterAsset(checkShip{})
+	m.RegisterFileSystem(newBarrierFS(wfs.fs, "a.json", "b.json"), 0)
+
+	// each goroutine loads one end of the cycle, and needs the other end
+	a, b := m.LoadAsync("a.json"), m.LoadAsync("b.json")
+	var errs []string
+	for _, results := range []<-chan asset.Result{a, b} {
+		select {
+		case result := <-results:
+			if result.Err != nil {
+				errs = append(errs, result.Err.Error())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("loading the two ends of a cycle at once did not finish")
+		}
+	}
+	if assert.NotEmpty(t, errs) {
+		assert.Regexp(t, `cyclic references (a.json -> b.json -> a.json|b.json -> a.json -> b.json)`, errs[0])
+	}
+}
+
+type roid struct {
+	Size   float64
+	Speed  float64
+	Colour string
+}
+
+func TestResolveFieldOrigin(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, roid{})
+
+	base := &roid{Size: 1, Speed: 1, Colour: "grey"}
+	assert.NoError(t, m.Save("base_roid.json", base))
+	big := &roid{Size: 10, Speed: 1, Colour: "grey"}
+	assert.NoError(t, m.SetParent(big, base))
+	assert.NoError(t, m.Save("big_roid.json", big))
+	apple := &roid{Size: 10, Speed: 1, Colour: "red"}
+	assert.NoError(t, m.SetParent(apple, big))
+	assert.NoError(t, m.Save("big_apple_roid.json", apple))
+
+	fresh := asset.NewManager()
+	fresh.SetEditorMode()
+	fresh.RegisterAsset(roid{})
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	loaded, err := fresh.Load("big_apple_roid.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"big_roid.json", "base_roid.json"}, fresh.ParentChain(loaded))
+
+	origin := func(field string) asset.Path {
+		path, err := fresh.GetLoadPathForAsset(fresh.ResolveFieldOrigin(loaded, field))
+		assert.NoError(t, err)
+		return path
+	}
+	assert.Equal(t, asset.Path("big_apple_roid.json"), origin("Colour"))
+	assert.Equal(t, asset.Path("big_roid.json"), origin("Size"))
+	assert.Equal(t, asset.Path("base_roid.json"), origin("Speed"))
+
+	baseLoaded, _ := fresh.Load("base_roid.json")
+	assert.Empty(t, fresh.ParentChain(baseLoaded))
+	assert.True(t, fresh.ResolveFieldOrigin(baseLoaded, "Speed") == baseLoaded)
+}
+
+func TestApplyAndRevertOverrides(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, roid{})
+
+	base := &roid{Size: 1, Speed: 1, Colour: "grey"}
+	assert.NoError(t, m.Save("base_roid.json", base))
+	big := &roid{Size: 10, Speed: 2, Colour: "red"}
+	assert.NoError(t, m.SetParent(big, base))
+	assert.NoError(t, m.Save("big_roid.json", big))
+	sibling := &roid{Size: 1, Speed: 1, Colour: "blue"}
+	assert.NoError(t, m.SetParent(sibling, base))
+	assert.NoError(t, m.Save("sibling.json", sibling))
+	assert.Equal(t, []string{"Colour", "Size", "Speed"}, m.ChildOverridePaths(big))
+
+	// push only the speed up, the sibling inherits it
+	assert.NoError(t, m.ApplyOverridesToParent(big, []string{"Speed"}))
+	assert.Equal(t, &roid{Size: 1, Speed: 2, Colour: "grey"}, base)
+	assert.Equal(t, &roid{Size: 1, Speed: 2, Colour: "blue"}, sibling)
+	assert.Equal(t, &roid{Size: 10, Speed: 2, Colour: "red"}, big)
+	assert.Equal(t, []string{"Colour", "Size"}, m.ChildOverridePaths(big))
+
+	// revert the colour, then everything
+	assert.NoError(t, m.RevertOverrides(big, []string{"Colour"}))
+	assert.Equal(t, &roid{Size: 10, Speed: 2, Colour: "grey"}, big)
+	assert.NoError(t, m.RevertOverrides(big, nil))
+	assert.Equal(t, &roid{Size: 1, Speed: 2, Colour: "grey"}, big)
+	assert.Empty(t, m.ChildOverridePaths(big))
+
+	// everything was saved
+	fresh := newTestManager(wfs, roid{})
+	for path, expected := range map[asset.Path]*roid{
+		"base_roid.json": base,
+		"big_roid.json":  big,
+		"sibling.json":   sibling,
+	} {
+		loaded, err := fresh.Load(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, loaded, path)
+	}
+
+	assert.Error(t, m.RevertOverrides(base, nil))
+	assert.Error(t, m.ApplyOverridesToParent(base, nil))
+}
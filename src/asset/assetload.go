@@ -1,17 +1,30 @@
 package asset
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
 )
 
-func (a *assetManagerImpl) Load(assetPath Path) (Asset, error) {
+// Load returns the asset at assetPath, loading it if needed.  Errors are
+// LoadErrors, joined if there are several.  When only some fields fail to
+// load the asset is still returned, loaded as far as possible, alongside the
+// error, and later Loads return it with the same error until it is saved or
+// reloaded.  Assets that refer to each other in a cycle, as references or
+// parents, fail to load with an error naming the cycle.
+func (a *Manager) Load(assetPath Path) (Asset, error) {
 	return a.LoadWithOptions(assetPath, LoadOptions{})
 }
 
-func (a *assetManagerImpl) NewInstance(assetToInstance Asset) (Asset, error) {
+func (a *Manager) NewInstance(assetToInstance Asset) (Asset, error) {
 	descriptor := a.GetAssetDescriptor(assetToInstance)
 	if descriptor == nil {
 		return nil, fmt.Errorf("Unable to find descriptor for asset %v", assetToInstance)
@@ -19,70 +32,238 @@ func (a *assetManagerImpl) NewInstance(assetToInstance Asset) (Asset, error) {
 
 	concreteOrigin := reflect.ValueOf(assetToInstance).Elem().Interface()
 	commonFormat := a.toCommonFormat(concreteOrigin)
-	instance, err := a.loadFromCommonFormat(descriptor.FullName, "", commonFormat, nil)
+	instance, err := a.loadFromCommonFormat(descriptor.FullName, "", commonFormat, nil, false, loadChain{})
 	if err != nil {
 		return nil, fmt.Errorf("Unable to create instance from %v", descriptor)
 	}
 	return instance, nil
 }
 
-func (a *assetManagerImpl) LoadWithOptions(assetPath Path, options LoadOptions) (Asset, error) {
-	// If we are able, don't reload an existing asset
-	alreadyLoadedAsset, loaded := a.LoadPathToAsset[assetPath]
-	if loaded && !options.ForceReload && !options.createInstance {
-		return alreadyLoadedAsset, nil
+func (a *Manager) LoadWithOptions(assetPath Path, options LoadOptions) (Asset, error) {
+	return a.load(assetPath, options, loadChain{})
+}
+
+// loadChain is the paths that are being loaded by one call to Load, the
+// first path loads the second and so on.  The loader is shared by the whole
+// chain, it records the load that the chain is waiting for.
+type loadChain struct {
+	paths  []Path
+	loader *loader
+}
+
+// loader is one call to Load and the loads nested in it.  Loads that wait
+// for each other in a cycle would never finish, so before a loader waits
+// for another's load it follows what that loader is waiting for.  The
+// fields are guarded by Manager.mu.
+type loader struct {
+	// waitingFor is the load that the loader is blocked on, or nil
+	waitingFor *pendingLoad
+	// paths is the loader's chain while it is blocked
+	paths []Path
+}
+
+// with returns a new chain that ends with path
+func (c loadChain) with(path Path) loadChain {
+	if c.loader == nil {
+		c.loader = &loader{}
 	}
+	c.paths = append(slices.Clip(c.paths), path)
+	return c
+}
 
-	if options.createInstance {
-		alreadyLoadedAsset = nil
+// cycleTo returns an error naming the cycle that waiting for pending would
+// make, or nil if there is none.  a.mu must be held.
+func (a *Manager) cycleTo(chain loadChain, pending *pendingLoad) error {
+	if chain.loader == nil {
+		// nothing is in flight on this chain, so nothing can wait for it
+		return nil
 	}
+	names := slices.Clone(chain.paths)
+	for p := pending; ; {
+		names = append(names, p.path)
+		if p.loader == chain.loader {
+			break
+		}
+		blocked := p.loader
+		if blocked.waitingFor == nil {
+			return nil
+		}
+		// the rest of the chain that is loading p
+		if i := slices.Index(blocked.paths, p.path); i >= 0 {
+			names = append(names, blocked.paths[i+1:]...)
+		}
+		p = blocked.waitingFor
+	}
+	start := slices.Index(names, names[len(names)-1])
+	var cycle []string
+	for _, name := range names[start:] {
+		cycle = append(cycle, string(name))
+	}
+	return fmt.Errorf("cyclic references %s", strings.Join(cycle, " -> "))
+}
 
-	data, err := assetManager.ReadFile(assetPath)
-	if err != nil {
-		return nil, err
+// load is LoadWithOptions for a load that was started while loading the
+// paths in chain
+func (a *Manager) load(assetPath Path, options LoadOptions, chain loadChain) (Asset, error) {
+	if options.createInstance {
+		instance, _, err := a.loadFromPath(assetPath, nil, chain)
+		return instance, inAsset(err, assetPath)
 	}
 
-	// load the on disk format and validate things
-	container := onDiskLoadFormat{}
-	err = json.Unmarshal(data, &container)
-	if err != nil {
-		return nil, err
+	a.mu.Lock()
+	// If we are able, don't reload an existing asset
+	alreadyLoadedAsset, loaded := a.LoadPathToAsset[assetPath]
+	if loaded && !options.ForceReload {
+		err := a.LoadErrors[assetPath]
+		a.mu.Unlock()
+		return alreadyLoadedAsset, err
 	}
 
-	loadedAsset, err := a.loadFromOnDiskLoadFormat(&container, alreadyLoadedAsset)
-	if err != nil {
-		return nil, err
+	// Another goroutine is loading this path, share its result
+	if pending, ok := a.inFlight[assetPath]; ok {
+		if err := a.cycleTo(chain, pending); err != nil {
+			a.mu.Unlock()
+			return nil, err
+		}
+		if chain.loader != nil {
+			chain.loader.waitingFor, chain.loader.paths = pending, chain.paths
+		}
+		a.mu.Unlock()
+		<-pending.done
+		if chain.loader != nil {
+			a.mu.Lock()
+			chain.loader.waitingFor, chain.loader.paths = nil, nil
+			a.mu.Unlock()
+		}
+		return pending.asset, pending.err
 	}
+	chain = chain.with(assetPath)
+	pending := &pendingLoad{done: make(chan struct{}), path: assetPath, loader: chain.loader}
+	a.inFlight[assetPath] = pending
+	a.mu.Unlock()
+
+	loadedAsset, file, err := a.loadFromPath(assetPath, alreadyLoadedAsset, chain)
+	err = inAsset(err, assetPath)
 
-	if err == nil && !options.createInstance {
+	a.mu.Lock()
+	if loadedAsset != nil {
 		// save the references to these assets to prevent future loading
 		a.AssetToLoadPath[loadedAsset] = assetPath
 		a.LoadPathToAsset[assetPath] = loadedAsset
+		a.AssetSources[loadedAsset] = file.source
+		if file.id != "" {
+			a.AssetIDs[loadedAsset] = file.id
+		}
+		if err != nil {
+			a.LoadErrors[assetPath] = err
+		} else {
+			delete(a.LoadErrors, assetPath)
+		}
 	}
-	return loadedAsset, nil
+	delete(a.inFlight, assetPath)
+	a.mu.Unlock()
+
+	pending.asset, pending.err = loadedAsset, err
+	close(pending.done)
+	return loadedAsset, err
+}
+
+// Result is the outcome of an asynchronous load
+type Result struct {
+	Asset Asset
+	Err   error
+}
+
+// LoadAsync loads assetPath on a new goroutine.  The returned channel
+// receives exactly one Result and is then closed.
+func (a *Manager) LoadAsync(assetPath Path) <-chan Result {
+	result := make(chan Result, 1)
+	go func() {
+		loaded, err := a.Load(assetPath)
+		result <- Result{Asset: loaded, Err: err}
+		close(result)
+	}()
+	return result
 }
 
-func (a *assetManagerImpl) loadFromOnDiskLoadFormat(container *onDiskLoadFormat, alreadyLoadedAsset Asset) (Asset, error) {
+// loadedFile describes the file that an asset was loaded from
+type loadedFile struct {
+	// source is the file system the file was read from
+	source fs.FS
+	// id is the asset's ID, if it has one
+	id string
+}
+
+// loadFromPath reads and builds the asset at assetPath, it does not
+// record the asset as loaded.  The asset is returned with the errors if
+// only some of its fields failed to load, along with where it was read
+// from.
+func (a *Manager) loadFromPath(assetPath Path, alreadyLoadedAsset Asset, chain loadChain) (Asset, loadedFile, error) {
+	data, source, err := a.readFileFrom(assetPath)
+	if err != nil {
+		return nil, loadedFile{}, err
+	}
+
+	// load the on disk format (json or binary) and validate things
+	container, err := decodeContainer(data)
+	if err != nil {
+		return nil, loadedFile{}, err
+	}
+
+	loaded, err := a.loadFromContainer(container, alreadyLoadedAsset, chain)
+	return loaded, loadedFile{source: source, id: container.ID}, err
+}
+
+func (a *Manager) loadFromOnDiskLoadFormat(container *onDiskLoadFormat, alreadyLoadedAsset Asset, chain loadChain) (Asset, error) {
 	var commonFormat any
 	err := json.Unmarshal(container.Inner, &commonFormat)
 	if err != nil {
 		return nil, err
 	}
+	return a.loadFromContainer(&onDiskSaveFormat{
+		Type:     container.Type,
+		ID:       container.ID,
+		Version:  container.Version,
+		Parent:   container.Parent,
+		ParentID: container.ParentID,
+		Inner:    commonFormat,
+	}, alreadyLoadedAsset, chain)
+}
+
+// loadFromContainer loads a container whose Inner is already in Common format
+func (a *Manager) loadFromContainer(container *onDiskSaveFormat, alreadyLoadedAsset Asset, chain loadChain) (Asset, error) {
+	if err := a.migrateContainer(container); err != nil {
+		return nil, err
+	}
 	parentPath := container.Parent
-	if parentPath == "" {
-		parentPath = a.ChildToParent[alreadyLoadedAsset]
+	if parentPath != "" {
+		parentPath = a.resolveReference(reference{Path: parentPath, ID: container.ParentID})
+	}
+	if parentPath == "" && alreadyLoadedAsset != nil {
+		parentPath = a.GetParent(alreadyLoadedAsset)
 	}
-	return a.loadFromCommonFormat(container.Type, parentPath, commonFormat, alreadyLoadedAsset)
+	// a new asset holds the defaults of its type, which the file may have
+	// been saved against, see OmitDefaults
+	merge := alreadyLoadedAsset == nil
+	return a.loadFromCommonFormat(container.Type, parentPath, container.Inner, alreadyLoadedAsset, merge, chain)
 }
 
-func (a *assetManagerImpl) loadFromCommonFormat(
+// loadFromCommonFormat loads commonFormat into alreadyLoadedAsset, or into a
+// new asset of assetType.  The values of the parent at parentPath are
+// loaded first and commonFormat is merged over them.  merge is true when
+// commonFormat only holds some of the values, and the asset already holds
+// the rest.  Otherwise maps are replaced rather than merged into, so that
+// a reloaded asset loses the keys that were removed from its file.
+func (a *Manager) loadFromCommonFormat(
 	assetType string,
 	parentPath Path,
 	commonFormat any,
-	alreadyLoadedAsset Asset) (Asset, error) {
+	alreadyLoadedAsset Asset,
+	merge bool,
+	chain loadChain) (Asset, error) {
 
-	assetDescriptor, ok := assetManager.AssetDescriptors[assetType]
-	if !ok {
+	assetDescriptor := a.descriptorForTypeName(assetType)
+	if assetDescriptor == nil {
 		return nil, fmt.Errorf("Unknown asset '%s' - is type registered?", assetType)
 	}
 
@@ -101,48 +282,61 @@ func (a *assetManagerImpl) loadFromCommonFormat(
 		return nil, fmt.Errorf("Load type mismatch.  Wanted %s, loaded %s", TType, assetType)
 	}
 
+	// problems with single fields are collected rather than stopping the load
+	var errs []error
+
 	// copy the parent into the child
 	// load the parent (if it has one) and copy into the child
 	if parentPath != "" {
-		if a.EditorMode && commonFormat != nil {
+		if a.isEditorMode() && commonFormat != nil {
 			overrides := newChildOverrides()
+			overrides.BuildFromCommonFormat(commonFormat, reflect.TypeOf(assetToLoadInto).Elem())
+			a.mu.Lock()
 			a.ChildAssetOverrides[assetToLoadInto] = overrides
-			overrides.BuildFromCommonFormat(commonFormat)
+			a.mu.Unlock()
 		}
 
-		parent, err := a.Load(parentPath)
-		if err != nil {
-			return nil, err
+		parent, err := a.load(parentPath, LoadOptions{}, chain)
+		if parent == nil {
+			return nil, fmt.Errorf("unable to load parent: %w", err)
 		}
+		// the parent's own problems are reported against the parent
+		errs = append(errs, err)
 		// we don't want the common format for a pointer to the parent, but
 		// to the real struct
 		parentConcrete := reflect.ValueOf(parent).Elem().Interface()
 		parentInCommonFormat := a.toCommonFormat(parentConcrete)
-		a.loadFromCommonFormat(assetType, "", parentInCommonFormat, assetToLoadInto)
+		a.loadFromCommonFormat(assetType, "", parentInCommonFormat, assetToLoadInto, false, chain)
+		merge = true
 
 		// set the parent for this asset
+		a.mu.Lock()
 		a.ChildToParent[assetToLoadInto] = parentPath
+		a.mu.Unlock()
 	}
 
-	var err error
 	// commonFormat can be nil - it means the whole object is default/inherited
 	if commonFormat != nil {
-		err = a.unmarshalCommonFormat(commonFormat, assetToLoadInto)
-		if err != nil {
-			return nil, err
-		}
+		errs = append(errs, a.unmarshalCommonFormat(commonFormat, assetToLoadInto, merge, chain))
 	}
 	//fmt.Printf("%v %#v\n", reflect.TypeOf(obj).Name(), obj)
+	if managerAware, ok := assetToLoadInto.(ManagerAwareAsset); ok {
+		managerAware.SetAssetManager(a)
+	}
 	if postLoad, ok := assetToLoadInto.(PostLoadingAsset); ok {
 		postLoad.PostLoad()
 	}
 
-	return assetToLoadInto, err
+	return assetToLoadInto, errors.Join(errs...)
 }
 
-func (a *assetManagerImpl) unmarshalCommonFormat(data any, v any) error {
-	context := &commonFormatContext{}
-	return a.unmarshalCommonFormatFromValues(reflect.ValueOf(data), reflect.ValueOf(v).Elem(), context)
+// unmarshalCommonFormat loads data into v, carrying on past fields that
+// fail.  The returned error joins a LoadError for each failed field.  When
+// merge is true the maps in data are merged into the maps that v holds.
+func (a *Manager) unmarshalCommonFormat(data any, v any, merge bool, chain loadChain) error {
+	context := &commonFormatContext{chain: chain, merge: merge}
+	context.addError(a.unmarshalCommonFormatFromValues(reflect.ValueOf(data), reflect.ValueOf(v).Elem(), context))
+	return errors.Join(context.errs...)
 }
 
 func safeLen(value reflect.Value) int {
@@ -158,13 +352,16 @@ func safeLen(value reflect.Value) int {
 // unmarshalCommonFormatFromValues accepts a source Value in Common format and a concrete
 // Go type in dest.
 // Common Format erases some type information about structs, so dest is reflected to recover types.
-func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
+func (a *Manager) unmarshalCommonFormatFromValues(
 	source reflect.Value,
 	dest reflect.Value,
 	context *commonFormatContext) error {
 	//fmt.Printf("source:%#v \nkind %s\n", source, source.Kind())
 	//fmt.Printf("dest:%#v \nkind %s\n-----\n", dest, dest.Kind())
 	t := dest.Type()
+	if s := a.serializerFor(t); s != nil {
+		return s.load(source, dest)
+	}
 	switch dest.Kind() {
 	case reflect.Interface:
 		//fmt.Println("Handle interface (fallthrough to pointer)")
@@ -192,7 +389,7 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 		var isLoadPath bool
 		var loadPathInfo map[string]any
 		if loadPath, ok := source.Interface().(*assetLoadPath); ok {
-			loadPathInfo = map[string]any{"Path": string(loadPath.Path)}
+			loadPathInfo = map[string]any{"Path": string(loadPath.Path), "ID": loadPath.ID}
 			isLoadPath = true
 		}
 
@@ -208,10 +405,16 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 			// Paths must load first
 			if pathAny, ok := loadPathInfo["Path"]; ok {
 				// This is a saved reference to another asset
-				path := pathAny.(string)
-				asset, err := a.Load(Path(path))
-				if err != nil {
-					return fmt.Errorf("unable to load asset at path %s", path)
+				id, _ := loadPathInfo["ID"].(string)
+				path := a.resolveReference(reference{Path: Path(pathAny.(string)), ID: id})
+				asset, err := a.load(path, LoadOptions{}, context.chain)
+				if asset == nil {
+					return fmt.Errorf("unable to load asset at path %s: %w", path, err)
+				}
+				// the referenced asset's own problems are reported against it
+				context.addError(err)
+				if !reflect.TypeOf(asset).AssignableTo(t) {
+					return fmt.Errorf("%s is a %T, which cannot be assigned to %s", path, asset, t)
 				}
 				dest.Set(reflect.ValueOf(asset))
 				return nil
@@ -225,25 +428,32 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 		}
 
 		if isDiskFormat {
-			inlineAsset, err := a.loadFromOnDiskLoadFormat(&diskLoadFormat, nil)
-			if err != nil {
-				return fmt.Errorf("unable to load inline asset")
+			inlineAsset, err := a.loadFromOnDiskLoadFormat(&diskLoadFormat, nil, context.chain)
+			if inlineAsset == nil {
+				return fmt.Errorf("unable to load inline asset: %w", err)
+			}
+			if !reflect.TypeOf(inlineAsset).AssignableTo(t) {
+				return fmt.Errorf("inline %T cannot be assigned to %s", inlineAsset, t)
 			}
 			dest.Set(reflect.ValueOf(inlineAsset))
-			return nil
+			// the inline asset's fields are inside of this one
+			return err
 		}
 
 		panic("Should not get here")
 
 	case reflect.Struct:
+		if source.Kind() != reflect.Map {
+			return fmt.Errorf("cannot load %v into %s, it is not a struct", source, t)
+		}
 		for i := 0; i < t.NumField(); i++ {
 			func(field reflect.StructField) {
 				context.Push(&field)
 				defer context.Pop()
-				if !field.IsExported() {
+				if !IsSavedField(&field) {
 					return
 				}
-				fieldToSet := dest.Field(i)
+				fieldToSet := FieldValue(dest, i)
 				name := field.Name
 				key := reflect.ValueOf(name)
 				dataToRead := source.MapIndex(key)
@@ -252,10 +462,58 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 					//log.Printf("dataToRead for key (%s) is missing, skipping", key)
 					return
 				}
-				a.unmarshalCommonFormatFromValues(dataToRead.Elem(), fieldToSet, context)
+				context.addError(a.unmarshalCommonFormatFromValues(dataToRead.Elem(), fieldToSet, context))
 			}(t.Field(i))
 		}
+	case reflect.Map:
+		m, ok := source.Interface().(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot load %v into %s, it is not a map", source, t)
+		}
+		if dest.IsNil() || !context.merge {
+			dest.Set(reflect.MakeMapWithSize(t, len(m)))
+		}
+		mapField := context.Peek()
+		for _, k := range sortedMapKeys(m) {
+			func() {
+				context.Push(mapKeyField(mapField, t, k))
+				defer context.Pop()
+				key, err := ParseMapKey(k, t.Key())
+				if err != nil {
+					context.addError(err)
+					return
+				}
+				if m[k] == nil {
+					// a key that the child removed from its parent
+					dest.SetMapIndex(key, reflect.Value{})
+					return
+				}
+				// start from the existing value, a child's map holds its
+				// parent's values for the keys it does not override
+				elem := reflect.New(t.Elem()).Elem()
+				if existing := dest.MapIndex(key); existing.IsValid() {
+					elem.Set(existing)
+				}
+				err = a.unmarshalCommonFormatFromValues(reflect.ValueOf(m[k]), elem, context)
+				context.addError(err)
+				dest.SetMapIndex(key, elem)
+			}()
+		}
 	case reflect.Slice:
+		if diff, ok := asSliceDiff(source.Interface()); ok {
+			// a child that changed only some of its parent's elements
+			return a.mergeSliceDiff(diff, dest, context)
+		}
+		if t.Elem().Kind() == reflect.Uint8 && source.IsValid() {
+			// byte slices are base64 in Common format, hex lines in
+			// json and raw in the binary format
+			decoded, err := decodeSavedBytes(source.Interface())
+			if err != nil {
+				return err
+			}
+			dest.SetBytes(bytes.Clone(decoded))
+			return nil
+		}
 		l := safeLen(source)
 		dest.Set(reflect.MakeSlice(dest.Type(), l, l))
 		fallthrough
@@ -271,14 +529,18 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 			//fmt.Printf("%v %v\n", decoded, string(decoded))
 			dest.SetBytes(decoded)
 		} else {
+			sliceField := context.Peek()
 			for i := 0; i < l; i++ {
 				indexToSet := dest.Index(i)
 				dataToRead := source.Index(i)
 				//fmt.Printf("Array Data %v\n", dataToRead)
-				a.unmarshalCommonFormatFromValues(dataToRead.Elem(), indexToSet, context)
+				context.Push(sliceElementField(sliceField, t, strconv.Itoa(i)))
+				context.addError(a.unmarshalCommonFormatFromValues(dataToRead.Elem(), indexToSet, context))
+				context.Pop()
 			}
 		}
 	default:
+		mismatch := func() error { return fmt.Errorf("cannot load %v into %s", source, t) }
 		if source.CanFloat() {
 			// Json treats all numerics as floats, so we must handle float
 			// to int conversion
@@ -288,16 +550,26 @@ func (a *assetManagerImpl) unmarshalCommonFormatFromValues(
 				dest.SetInt(int64(source.Float()))
 			} else if dest.CanUint() {
 				dest.SetUint(uint64(source.Float()))
+			} else {
+				return mismatch()
 			}
 		} else if source.Kind() == reflect.String {
 			// the asset.Path type is a string, but we can't just do
 			// v.Set, instead we need to use SetString.  Other types
 			// that are aliased like this might also break
+			if dest.Kind() != reflect.String {
+				return mismatch()
+			}
 			dest.SetString(source.String())
 		} else if source.Kind() == reflect.Bool {
+			if dest.Kind() != reflect.Bool {
+				return mismatch()
+			}
 			dest.SetBool(source.Bool())
-		} else {
+		} else if source.IsValid() && source.Type().AssignableTo(t) {
 			dest.Set(source)
+		} else {
+			return mismatch()
 		}
 	}
 
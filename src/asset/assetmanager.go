@@ -5,26 +5,47 @@ import (
 	"fmt"
 	"io/fs"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slices"
 )
 
 // onDiskLoadFormat must be the same as onDiskSaveFormat, except for the type of Inner
 type onDiskLoadFormat struct {
-	Type   string
-	Parent Path
-	Inner  json.RawMessage
+	Type     string
+	ID       string `json:",omitempty"`
+	Version  int    `json:",omitempty"`
+	Parent   Path
+	ParentID string `json:",omitempty"`
+	Inner    json.RawMessage
 }
 
 // onDiskSaveFormat must be the same as onDiskLoadFormat, except for the type of Inner
 type onDiskSaveFormat struct {
-	Type   string
-	Parent Path
-	Inner  interface{}
+	Type string
+	// ID is the stable id of a saved asset, it does not change when the
+	// file is moved.  Inline assets do not have one.
+	ID       string `json:",omitempty"`
+	Version  int    `json:",omitempty"`
+	Parent   Path
+	ParentID string `json:",omitempty"`
+	Inner    interface{}
 }
 
-type assetManagerImpl struct {
+// Manager owns a complete, isolated set of assets.  It holds the file systems
+// that assets are read from and written to, the registered asset types, and
+// the bookkeeping for every asset it has loaded.  Two Managers never share
+// loaded assets, so a process may run several independent content sets, for
+// example an editor alongside a headless validation pass.
+//
+// The package level functions (asset.Load, asset.Save, etc) operate on the
+// DefaultManager.
+//
+// A Manager is safe for concurrent use.  Loads of the same Path that overlap
+// in time share a single load.
+type Manager struct {
 	FileSystems         []*fsWrapper
 	AssetDescriptors    map[string]*AssetDescriptor
 	AssetDescriptorList []*AssetDescriptor
@@ -41,12 +62,67 @@ type assetManagerImpl struct {
 	// ChildToParent maps a child asset to its parent
 	ChildToParent map[Asset]Path
 
+	// AssetSources maps a loaded asset to the registered file system
+	// that its bytes were read from
+	AssetSources map[Asset]fs.FS
+
+	// Handles counts the unreleased Handles to each loaded asset
+	Handles map[Asset]int
+
+	// AssetIDs maps a loaded or saved asset to its stable ID
+	AssetIDs map[Asset]string
+
+	// LoadErrors holds the errors of the assets that only partly loaded,
+	// so that every Load of them returns the error
+	LoadErrors map[Path]error
+
 	EditorMode bool
 
+	// SaveFormat is the encoding that Save writes.  Load accepts every
+	// format regardless of this setting.
+	SaveFormat SaveFormat
+
+	// OmitDefaults saves only the fields of an asset without a parent that
+	// differ from the defaults of its type.  When it is false only the
+	// fields with the zero value are left out.
+	OmitDefaults bool
+
 	// ChildAssetOverrides stores metadata about a child asset and
 	// the feilds that it overrides.  If an asset is not a child
 	// it will not be in this map.
 	ChildAssetOverrides map[Asset]*childOverrides
+
+	// Migrations maps a type name to the migrations that upgrade
+	// that type, indexed by the version they upgrade from
+	Migrations map[string]map[int]MigrationFunc
+
+	// Serializers save and load the values of a type in a form of their
+	// own, see RegisterSerializer
+	Serializers map[reflect.Type]*serializer
+
+	// dependencies is built on demand, nil means it must be rebuilt
+	dependencies *dependencyIndex
+
+	// watchers are told about the files the Manager writes itself
+	watchers []*Watcher
+
+	// mu guards all of the fields above
+	mu sync.RWMutex
+
+	// inFlight holds the loads that are currently running so that
+	// concurrent requests for the same Path can wait on them
+	inFlight map[Path]*pendingLoad
+}
+
+// pendingLoad is a load that is in progress.  done is closed once
+// asset and err are valid.
+type pendingLoad struct {
+	done  chan struct{}
+	asset Asset
+	err   error
+	// path is being loaded by loader
+	path   Path
+	loader *loader
 }
 
 type fsWrapper struct {
@@ -54,20 +130,74 @@ type fsWrapper struct {
 	Priority   int
 }
 
-var assetManager = newAssetManagerImpl()
+var defaultManager = NewManager()
+
+// DefaultManager returns the Manager used by the package level functions.
+func DefaultManager() *Manager {
+	return defaultManager
+}
 
-func newAssetManagerImpl() *assetManagerImpl {
-	return &assetManagerImpl{
+// NewManager creates an empty Manager.  Asset types and file systems must
+// be registered with the new Manager before it can load anything.
+func NewManager() *Manager {
+	return &Manager{
 		FileSystems:         []*fsWrapper{},
 		AssetDescriptors:    map[string]*AssetDescriptor{},
 		AssetToLoadPath:     map[Asset]Path{},
 		LoadPathToAsset:     map[Path]Asset{},
 		ChildToParent:       map[Asset]Path{},
+		AssetSources:        map[Asset]fs.FS{},
+		Handles:             map[Asset]int{},
+		AssetIDs:            map[Asset]string{},
+		LoadErrors:          map[Path]error{},
 		ChildAssetOverrides: map[Asset]*childOverrides{},
+		Migrations:          map[string]map[int]MigrationFunc{},
+		inFlight:            map[Path]*pendingLoad{},
 	}
 }
 
-func (a *assetManagerImpl) AddFS(wrapper *fsWrapper) error {
+func (a *Manager) RegisterFileSystem(filesystem fs.FS, priority int) error {
+	wrapped := &fsWrapper{FileSystem: filesystem, Priority: priority}
+	return a.AddFS(wrapped)
+}
+
+func (a *Manager) RegisterWritableFileSystem(filesystem WriteableFileSystem) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.WriteFS = filesystem
+	return nil
+}
+
+// SetSaveFormat sets the encoding that Save writes
+func (a *Manager) SetSaveFormat(format SaveFormat) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.SaveFormat = format
+}
+
+// SetOmitDefaults is the Manager version of asset.SetOmitDefaults
+func (a *Manager) SetOmitDefaults(omit bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.OmitDefaults = omit
+}
+
+func (a *Manager) omitDefaults() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.OmitDefaults
+}
+
+// SetEditorMode is the Manager version of asset.SetEditorMode
+func (a *Manager) SetEditorMode() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.EditorMode = true
+}
+
+func (a *Manager) AddFS(wrapper *fsWrapper) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.FileSystems = append(a.FileSystems, wrapper)
 	slices.SortFunc(a.FileSystems, func(a, b *fsWrapper) int {
 		return a.Priority - b.Priority
@@ -75,21 +205,63 @@ func (a *assetManagerImpl) AddFS(wrapper *fsWrapper) error {
 	return nil
 }
 
-func (a *assetManagerImpl) ReadFile(path Path) ([]byte, error) {
-	for _, fsys := range a.FileSystems {
+func (a *Manager) ReadFile(path Path) ([]byte, error) {
+	data, _, err := a.readFileFrom(path)
+	return data, err
+}
+
+// readFileFrom is ReadFile, but also returns the file system that the
+// file was read from.  File systems are searched in priority order, so a
+// file in a patch or mod shadows the same path in the base content.
+func (a *Manager) readFileFrom(path Path) ([]byte, fs.FS, error) {
+	for _, fsys := range a.fileSystems() {
 		data, err := fs.ReadFile(fsys.FileSystem, string(path))
 		if err == nil {
-			return data, nil
+			return data, fsys.FileSystem, nil
 		}
 	}
-	return nil, fmt.Errorf("Unable to find path (%s) in any registered FS ", path)
+	return nil, nil, fmt.Errorf("Unable to find path (%s) in any registered FS ", path)
+}
+
+// SourceFileSystem returns the registered file system that the loaded
+// asset was read from, or nil if it was not loaded from one
+func (a *Manager) SourceFileSystem(asset Asset) fs.FS {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.AssetSources[asset]
 }
 
-func (a *assetManagerImpl) WalkFiles(fn fs.WalkDirFunc) error {
+// fileSystems returns a snapshot of the registered file systems, so
+// that they can be read without holding the lock.
+func (a *Manager) fileSystems() []*fsWrapper {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return slices.Clone(a.FileSystems)
+}
+
+// WalkFiles calls fn for every file and directory in the registered file
+// systems, in priority order.  Each path is only visited once, a path that
+// is shadowed by a higher priority file system is skipped, so fn sees the
+// same files that ReadFile would read.
+func (a *Manager) WalkFiles(fn fs.WalkDirFunc) error {
 	var e error
-	for _, fsys := range a.FileSystems {
+	seen := map[string]bool{}
+	skipped := map[string]bool{}
+	for _, fsys := range a.fileSystems() {
 		err := fs.WalkDir(fsys.FileSystem, ".", func(path string, d fs.DirEntry, err error) error {
+			if seen[path] {
+				// directories are still walked, they may hold files that
+				// only exist in this file system
+				if skipped[path] {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			seen[path] = true
 			e = fn(path, d, err)
+			if e == fs.SkipDir && d != nil && d.IsDir() {
+				skipped[path] = true
+			}
 			return e
 		})
 		if err != nil {
@@ -105,7 +277,7 @@ func (a *assetManagerImpl) WalkFiles(fn fs.WalkDirFunc) error {
 
 // FilterFilesByType will return all the assets that have the exact type as typ.  If typ
 // is an interface, return all the files that implement the interface
-func (a *assetManagerImpl) FilterFilesByType(typ reflect.Type) ([]string, error) {
+func (a *Manager) FilterFilesByType(typ reflect.Type) ([]string, error) {
 	if typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
 	}
@@ -117,17 +289,15 @@ func (a *assetManagerImpl) FilterFilesByType(typ reflect.Type) ([]string, error)
 		if !strings.HasSuffix(path, ".json") {
 			return nil
 		}
-		// TODO - unify this with the load snippet in assetload.go
-		data, err := assetManager.ReadFile(Path(path))
+		data, err := a.ReadFile(Path(path))
 		if err != nil {
 			return err
 		}
-		container := onDiskLoadFormat{}
-		err = json.Unmarshal(data, &container)
+		container, err := decodeContainer(data)
 		if err != nil {
 			return nil
 		}
-		if desc, ok := a.AssetDescriptors[container.Type]; ok {
+		if desc := a.descriptorForTypeName(container.Type); desc != nil {
 			if matchesOrImplements(typ, desc.Type) {
 				ret = append(ret, path)
 			}
@@ -154,10 +324,12 @@ func matchesOrImplements(a, b reflect.Type) bool {
 
 // FilterAssetDecriptorsByType will return all the asset descriptors that have the exact type as typ.  If typ
 // is an interface, return all the descriptors that implement the interface
-func (a *assetManagerImpl) FilterAssetDescriptorsByType(typ reflect.Type) []*AssetDescriptor {
+func (a *Manager) FilterAssetDescriptorsByType(typ reflect.Type) []*AssetDescriptor {
 	if typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
 	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	var ret []*AssetDescriptor
 	for _, desc := range a.AssetDescriptorList {
 		if matchesOrImplements(typ, desc.Type) {
@@ -167,7 +339,7 @@ func (a *assetManagerImpl) FilterAssetDescriptorsByType(typ reflect.Type) []*Ass
 	return ret
 }
 
-func (a *assetManagerImpl) RegisterAssetFactory(zeroAsset any, factoryFunction FactoryFunc) {
+func (a *Manager) RegisterAssetFactory(zeroAsset any, factoryFunction FactoryFunc) {
 	zeroType := reflect.TypeOf(zeroAsset)
 	if zeroType.Kind() != reflect.Struct {
 		log.Panicf("RegisterAssetFactory must be called with a concrete type that is a struct.  This is a programming error - %v", zeroAsset)
@@ -190,6 +362,8 @@ func (a *assetManagerImpl) RegisterAssetFactory(zeroAsset any, factoryFunction F
 		Create:   createFunc,
 		Type:     zeroType,
 	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.AssetDescriptors[typeName] = descriptor
 	a.AssetDescriptorList = append(a.AssetDescriptorList, descriptor)
 	slices.SortFunc(a.AssetDescriptorList, func(a, b *AssetDescriptor) int {
@@ -197,39 +371,71 @@ func (a *assetManagerImpl) RegisterAssetFactory(zeroAsset any, factoryFunction F
 	})
 }
 
-func (a *assetManagerImpl) GetAssetDescriptor(target Asset) *AssetDescriptor {
+func (a *Manager) RegisterAsset(zeroAsset any) {
+	a.RegisterAssetFactory(zeroAsset, func() (Asset, error) {
+		zeroType := reflect.TypeOf(zeroAsset)
+		zero := reflect.New(zeroType)
+		return zero.Interface().(Asset), nil
+	})
+}
+
+func (a *Manager) GetAssetDescriptors() []*AssetDescriptor {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return slices.Clone(a.AssetDescriptorList)
+}
+
+func (a *Manager) GetAssetDescriptor(target Asset) *AssetDescriptor {
 	_, typeName := ObjectTypeName(target)
+	return a.descriptorForTypeName(typeName)
+}
+
+func (a *Manager) descriptorForTypeName(typeName string) *AssetDescriptor {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return a.AssetDescriptors[typeName]
 }
 
-func (a *assetManagerImpl) SetParent(child Asset, parent Asset) error {
-	if !a.EditorMode {
+func (a *Manager) isEditorMode() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.EditorMode
+}
+
+func (a *Manager) SetParent(child Asset, parent Asset) error {
+	if !a.isEditorMode() {
 		panic("Must be in editor mode to call SetParent")
 	}
 
 	if parent == nil {
+		a.mu.Lock()
 		delete(a.ChildToParent, child)
 		delete(a.ChildAssetOverrides, child)
+		a.mu.Unlock()
 		return nil
 	}
 
-	parentPath, ok := a.AssetToLoadPath[parent]
-	if !ok {
+	parentPath, err := a.GetLoadPathForAsset(parent)
+	if err != nil {
 		return fmt.Errorf("parent is not a loaded asset %v", parent)
 	}
 
-	_, hadParent := a.ChildToParent[child]
+	hadParent := a.GetParent(child) != ""
 	if !hadParent {
 		// no parent case
 		diffs := a.findDiffsFromParent(parent, child)
 		if diffs != nil {
 			overrides := newChildOverrides()
-			overrides.BuildFromCommonFormat(diffs)
+			overrides.BuildFromCommonFormat(diffs, reflect.TypeOf(child).Elem())
+			a.mu.Lock()
 			a.ChildAssetOverrides[child] = overrides
+			a.mu.Unlock()
 		}
 	}
 
+	a.mu.Lock()
 	a.ChildToParent[child] = parentPath
+	a.mu.Unlock()
 	if hadParent {
 		a.refreshParentValuesForChild(child, parentPath)
 	}
@@ -237,10 +443,31 @@ func (a *assetManagerImpl) SetParent(child Asset, parent Asset) error {
 	return nil
 }
 
-func (a *assetManagerImpl) GetParent(child Asset) Path {
+func (a *Manager) GetParent(child Asset) Path {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return a.ChildToParent[child]
 }
 
+func (a *Manager) GetParentAsset(child Asset) Asset {
+	parentPath := a.GetParent(child)
+	if parentPath == "" {
+		return nil
+	}
+	parent, _ := a.Load(parentPath)
+	return parent
+}
+
+func (a *Manager) GetLoadPathForAsset(target Asset) (Path, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	path, ok := a.AssetToLoadPath[target]
+	if !ok {
+		return path, fmt.Errorf("Asset not loaded")
+	}
+	return path, nil
+}
+
 type OverrideEnableType uint8
 
 const (
@@ -248,19 +475,58 @@ const (
 	OverrideDisable
 )
 
-func SetChildOverrideForField(child Asset, pathToField string, enable OverrideEnableType) error {
-	return assetManager.SetChildOverrideForField(child, pathToField, enable)
-}
-
-func ChildOverridesField(child Asset, pathToField string) bool {
-	overrides := assetManager.ChildAssetOverrides[child]
+func (a *Manager) ChildOverridesField(child Asset, pathToField string) bool {
+	overrides := a.childOverridesFor(child)
 	if overrides == nil {
 		return false
 	}
 	return overrides.PathHasOverride(pathToField)
 }
 
-func (a *assetManagerImpl) SetChildOverrideForField(child Asset, pathToField string, enable OverrideEnableType) error {
+// ParentChain returns the paths of child's parent, its parent's parent and
+// so on, nearest first
+func (a *Manager) ParentChain(child Asset) []Path {
+	var chain []Path
+	seen := map[Asset]bool{}
+	for current := child; current != nil && !seen[current]; {
+		seen[current] = true
+		parentPath := a.GetParent(current)
+		if parentPath == "" {
+			break
+		}
+		chain = append(chain, parentPath)
+		current, _ = a.Load(parentPath)
+	}
+	return chain
+}
+
+// ResolveFieldOrigin returns the asset that supplies the value of the field
+// at fieldPath (as used by ChildOverridesField) for child.  Starting at child
+// the parent chain is walked until an asset that overrides the field, or
+// that has no parent, is found.  Overrides are only tracked in editor mode.
+func (a *Manager) ResolveFieldOrigin(child Asset, fieldPath string) Asset {
+	seen := map[Asset]bool{}
+	current := child
+	for !seen[current] {
+		seen[current] = true
+		parentPath := a.GetParent(current)
+		if parentPath == "" {
+			break
+		}
+		if overrides := a.childOverridesFor(current); overrides != nil && overrides.coversPath(fieldPath) {
+			break
+		}
+		parent, _ := a.Load(parentPath)
+		if parent == nil {
+			break
+		}
+		current = parent
+	}
+	return current
+}
+
+func (a *Manager) SetChildOverrideForField(child Asset, pathToField string, enable OverrideEnableType) error {
+	a.mu.Lock()
 	overrides := a.ChildAssetOverrides[child]
 	if enable == OverrideEnable {
 		if overrides == nil {
@@ -269,11 +535,122 @@ func (a *assetManagerImpl) SetChildOverrideForField(child Asset, pathToField str
 		}
 		overrides.AddPath(pathToField)
 	}
+	a.mu.Unlock()
 	if enable == OverrideDisable && overrides != nil {
 		overrides.RemovePath(pathToField)
-		if parentPath, ok := a.ChildToParent[child]; ok {
+		if parentPath := a.GetParent(child); parentPath != "" {
 			a.refreshParentValuesForChild(child, parentPath)
 		}
 	}
 	return nil
 }
+
+func (a *Manager) childOverridesFor(child Asset) *childOverrides {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.ChildAssetOverrides[child]
+}
+
+// ChildOverridePaths returns the paths of the fields that child overrides,
+// in order
+func (a *Manager) ChildOverridePaths(child Asset) []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	overrides := a.ChildAssetOverrides[child]
+	if overrides == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(overrides.overrides))
+	for path := range overrides.overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// selectedOverrides returns the overrides of child that are in fieldPaths,
+// or all of them when fieldPaths is nil
+func (a *Manager) selectedOverrides(child Asset, fieldPaths []string) *childOverrides {
+	selected := newChildOverrides()
+	for _, path := range a.ChildOverridePaths(child) {
+		if fieldPaths == nil || slices.Contains(fieldPaths, path) {
+			selected.AddPath(path)
+		}
+	}
+	return selected
+}
+
+// ApplyOverridesToParent copies the values of the fields at fieldPaths,
+// or of every field when fieldPaths is nil, that child overrides into its
+// parent.  The child then inherits those values instead of overriding
+// them.  The parent and child are saved, which refreshes the parent's
+// other children.
+func (a *Manager) ApplyOverridesToParent(child Asset, fieldPaths []string) error {
+	parent := a.GetParentAsset(child)
+	if parent == nil {
+		return fmt.Errorf("asset has no parent to apply overrides to")
+	}
+	parentPath := a.GetParent(child)
+	selected := a.selectedOverrides(child, fieldPaths)
+	if selected.Empty() {
+		return nil
+	}
+
+	childConcrete := reflect.ValueOf(child).Elem().Interface()
+	values := a.toCommonFormatInternal(childConcrete, &commonFormatContext{overrides: selected})
+	desc := a.GetAssetDescriptor(parent)
+	if _, err := a.loadFromCommonFormat(desc.FullName, "", values, parent, true, loadChain{}); err != nil {
+		return err
+	}
+	if a.GetParent(parent) != "" {
+		// the parent now overrides its own parent
+		for path := range selected.overrides {
+			a.SetChildOverrideForField(parent, path, OverrideEnable)
+		}
+	}
+
+	a.mu.Lock()
+	if overrides := a.ChildAssetOverrides[child]; overrides != nil {
+		for path := range selected.overrides {
+			overrides.RemovePath(path)
+		}
+	}
+	a.mu.Unlock()
+
+	// saving the parent refreshes all of its children
+	if err := a.Save(parentPath, parent); err != nil {
+		return err
+	}
+	return a.saveIfLoaded(child)
+}
+
+// RevertOverrides stops child from overriding the fields at fieldPaths, or
+// every field when fieldPaths is nil, so they take their parent's values
+// again.  The child is saved, which refreshes its own children.
+func (a *Manager) RevertOverrides(child Asset, fieldPaths []string) error {
+	parentPath := a.GetParent(child)
+	if parentPath == "" {
+		return fmt.Errorf("asset has no parent to revert to")
+	}
+	selected := a.selectedOverrides(child, fieldPaths)
+	a.mu.Lock()
+	if overrides := a.ChildAssetOverrides[child]; overrides != nil {
+		for path := range selected.overrides {
+			overrides.RemovePath(path)
+		}
+	}
+	a.mu.Unlock()
+	a.refreshParentValuesForChild(child, parentPath)
+	return a.saveIfLoaded(child)
+}
+
+// saveIfLoaded saves target to the path it was loaded from.  Assets that
+// were not loaded from a path, such as inline assets, are saved with the
+// asset that holds them.
+func (a *Manager) saveIfLoaded(target Asset) error {
+	path, err := a.GetLoadPathForAsset(target)
+	if err != nil {
+		return nil
+	}
+	return a.Save(path, target)
+}
@@ -2,32 +2,48 @@ package asset
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
-func (a *assetManagerImpl) Save(path Path, toSave Asset) error {
+func (a *Manager) Save(path Path, toSave Asset) error {
+	// binary assets keep the .json extension so that references
+	// to them do not change when content is cooked
+	if !strings.HasSuffix(string(path), ".json") {
+		path = path + ".json"
+	}
 	container, err := a.toDiskFormat(toSave)
 	if err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(container, "", "  ")
+	container.ID = a.idForSave(toSave, path)
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	format := a.SaveFormat
+	a.mu.RUnlock()
+	if format == SaveFormatBinary {
+		container = a.withRawBytes(container)
+	}
+	data, err := a.encodeContainer(container, format)
 	if err != nil {
 		return err
 	}
-
-	if !strings.HasSuffix(string(path), ".json") {
-		path = path + ".json"
-	}
-	err = assetManager.WriteFS.WriteFile(path, data)
+	err = writeFS.WriteFile(path, data)
 
 	if err != nil {
 		return err
 	}
+	a.wrote(path)
+	a.mu.Lock()
 	a.AssetToLoadPath[toSave] = path
 	a.LoadPathToAsset[path] = toSave
+	a.AssetIDs[toSave] = container.ID
+	// the file now holds what was loaded, so it loads without errors
+	delete(a.LoadErrors, path)
+	a.dependencies = nil
+	a.mu.Unlock()
 
 	a.reloadChildAssets(path, toSave)
 	return nil
@@ -35,7 +51,7 @@ func (a *assetManagerImpl) Save(path Path, toSave Asset) error {
 
 // toDiskFormat converts toSave into Common format and wraps it with
 // the on-disk format
-func (a *assetManagerImpl) toDiskFormat(toSave Asset) (*onDiskSaveFormat, error) {
+func (a *Manager) toDiskFormat(toSave Asset) (*onDiskSaveFormat, error) {
 	// toSave must be a pointer, but the top level needs to be
 	// saved as a struct
 	if reflect.TypeOf(toSave).Kind() != reflect.Pointer {
@@ -47,7 +63,18 @@ func (a *assetManagerImpl) toDiskFormat(toSave Asset) (*onDiskSaveFormat, error)
 	commonFormat := a.toCommonFormat(structToSave.Interface())
 
 	var parentCommonFormat any
-	if parentPath, ok := a.ChildToParent[toSave]; ok {
+	var parentID string
+	parentPath := a.GetParent(toSave)
+	if parentPath == "" && a.omitDefaults() {
+		// the type's defaults are treated as the parent, so only the
+		// fields that differ from them are saved
+		if desc := a.GetAssetDescriptor(toSave); desc != nil {
+			if defaults, err := desc.Create(); err == nil && defaults != nil {
+				parentCommonFormat = a.toCommonFormat(reflect.ValueOf(defaults).Elem().Interface())
+			}
+		}
+	}
+	if parentPath != "" {
 		parent, err := a.Load(parentPath)
 		if err != nil {
 			return nil, err
@@ -55,19 +82,22 @@ func (a *assetManagerImpl) toDiskFormat(toSave Asset) (*onDiskSaveFormat, error)
 		if parent != nil {
 			parentConcrete := reflect.ValueOf(parent).Elem().Interface()
 			parentCommonFormat = a.toCommonFormat(parentConcrete)
+			parentID = a.AssetID(parent)
 		}
 	}
 
-	diffsFromParent := findDiffsFromParentCommonFormat(parentCommonFormat, commonFormat)
+	diffsFromParent := a.findDiffsFromParentCommonFormat(parentCommonFormat, commonFormat, structToSave.Type(), nil)
 	_, fullname := ObjectTypeName(toSave)
-	if _, ok := a.AssetDescriptors[fullname]; !ok {
+	if a.descriptorForTypeName(fullname) == nil {
 		return nil, fmt.Errorf("Type %s is not registered with the asset system", fullname)
 	}
 
 	container := onDiskSaveFormat{
-		Type:   fullname,
-		Inner:  diffsFromParent,
-		Parent: a.ChildToParent[toSave],
+		Type:     fullname,
+		Version:  a.currentVersion(fullname),
+		Inner:    diffsFromParent,
+		Parent:   parentPath,
+		ParentID: parentID,
 	}
 
 	return &container, nil
@@ -75,16 +105,26 @@ func (a *assetManagerImpl) toDiskFormat(toSave Asset) (*onDiskSaveFormat, error)
 
 // reloadChildAssets walks all children assets and force-reload
 // Intended to be called after a parent asset has been saved.
-func (a *assetManagerImpl) reloadChildAssets(path Path, parent Asset) {
+func (a *Manager) reloadChildAssets(path Path, parent Asset) {
+	var children []Asset
+	a.mu.RLock()
 	for childAsset, parentPath := range a.ChildToParent {
 		if path == parentPath {
-			a.refreshParentValuesForChild(childAsset, parentPath)
+			children = append(children, childAsset)
 		}
 	}
+	a.mu.RUnlock()
+	for _, childAsset := range children {
+		a.refreshParentValuesForChild(childAsset, path)
+	}
 }
 
-func (a *assetManagerImpl) refreshParentValuesForChild(childAsset Asset, parentPath Path) {
-	overrides := a.ChildAssetOverrides[childAsset]
+func (a *Manager) refreshParentValuesForChild(childAsset Asset, parentPath Path) {
+	overrides := a.childOverridesFor(childAsset)
+	if overrides == nil {
+		// a child that overrides nothing, every value comes from the parent
+		overrides = newChildOverrides()
+	}
 
 	// we don't to convert the *interface* to commonFormat, we want the
 	// struct that the asset is really referring to in commonFormat
@@ -92,17 +132,35 @@ func (a *assetManagerImpl) refreshParentValuesForChild(childAsset Asset, parentP
 	child := a.toCommonFormatInternal(c, &commonFormatContext{overrides: overrides})
 
 	desc := a.GetAssetDescriptor(childAsset)
-	a.loadFromCommonFormat(desc.FullName, parentPath, child, childAsset)
+	a.loadFromCommonFormat(desc.FullName, parentPath, child, childAsset, false, loadChain{})
 }
 
+// assetLoadPath is a saved reference to another asset.  Path is a hint
+// when the asset has an ID.
 type assetLoadPath struct {
 	Type string
 	Path Path
+	ID   string `json:",omitempty"`
 }
 
 type commonFormatContext struct {
 	stack     []*reflect.StructField
 	overrides *childOverrides
+
+	// chain is the paths being loaded, see loadChain
+	chain loadChain
+	// merge is true when loading merges maps rather than replacing them
+	merge bool
+
+	// errs collects the problems found while unmarshalling
+	errs []error
+}
+
+// addError records err against the current field
+func (b *commonFormatContext) addError(err error) {
+	if err != nil {
+		b.errs = append(b.errs, inField(err, b.StackPath()))
+	}
 }
 
 func (b *commonFormatContext) Push(sf *reflect.StructField) {
@@ -121,14 +179,11 @@ func (b *commonFormatContext) Peek() *reflect.StructField {
 }
 
 func (b *commonFormatContext) StackPath() string {
-	var result strings.Builder
-	for i, field := range b.stack {
-		result.WriteString(field.Name)
-		if i < len(b.stack)-1 {
-			result.WriteString(".")
-		}
+	result := ""
+	for _, field := range b.stack {
+		result = joinField(result, field.Name)
 	}
-	return result.String()
+	return result
 }
 
 // toCommonFormat takes an object and converts it to the internal Common format
@@ -141,20 +196,33 @@ func (b *commonFormatContext) StackPath() string {
 //   - pointers are replaced by either
 //     a) savedAssetContainers for "inline" members OR
 //     b) assetLoadPath so normal assets can be loaded
-//   - structs are replaced with map[string]any for all exported fields
+//   - structs are replaced with map[string]any for all exported fields, and
+//     unexported fields with a `flat:"save"` tag
+//   - maps are replaced with map[string]any, integer keys become strings
 //   - slices of bytes are uuencoded to strings
+//   - slices with a `flat:"sort"` tag are sorted, see sortCommonList
+//   - types with a registered serializer are replaced by what it returns
+//   - a child's slices are saved as a sliceDiff when it only overrides some
+//     of the elements
 //   - everything else remains the same
-func (a *assetManagerImpl) toCommonFormat(obj any) any {
+func (a *Manager) toCommonFormat(obj any) any {
 	return a.toCommonFormatInternal(obj, &commonFormatContext{})
 }
 
-func (a *assetManagerImpl) toCommonFormatInternal(obj any, context *commonFormatContext) any {
+func (a *Manager) toCommonFormatInternal(obj any, context *commonFormatContext) any {
 	if obj == nil {
 		return nil
 	}
 	path := context.StackPath()
-	isPathOverridden := context.overrides == nil || context.overrides.PathHasOverride(path)
+	isPathOverridden := context.overrides == nil || context.overrides.coversPath(path)
 	t := reflect.TypeOf(obj)
+	if s := a.serializerFor(t); s != nil {
+		// serialized values are leaves, a child overrides all of it
+		if context.overrides != nil && !context.overrides.coversPathOrInside(path) {
+			return nil
+		}
+		return s.save(obj)
+	}
 	switch t.Kind() {
 	case reflect.Pointer:
 		// Save inline assets
@@ -168,11 +236,12 @@ func (a *assetManagerImpl) toCommonFormatInternal(obj any, context *commonFormat
 			}
 		}
 		// save references to known assets
-		if path, ok := a.AssetToLoadPath[obj]; ok {
+		if path, err := a.GetLoadPathForAsset(obj); err == nil {
 			_, fullname := ObjectTypeName(obj)
 			return &assetLoadPath{
 				Type: fullname,
 				Path: path,
+				ID:   a.AssetID(obj),
 			}
 		}
 		log.Printf("Field '%s' will not be saved.  Pointer is not inline, nor to a known asset", context.StackPath())
@@ -183,10 +252,10 @@ func (a *assetManagerImpl) toCommonFormatInternal(obj any, context *commonFormat
 		v := reflect.ValueOf(obj)
 		for i := 0; i < t.NumField(); i++ {
 			structField := t.Field(i)
-			if !structField.IsExported() { // ignore unexported fields
+			if !IsSavedField(&structField) { // ignore unexported fields
 				continue
 			}
-			field := v.Field(i)
+			field := FieldValue(v, i)
 			context.Push(&structField)
 			c := a.toCommonFormatInternal(field.Interface(), context)
 			if c != nil {
@@ -195,11 +264,46 @@ func (a *assetManagerImpl) toCommonFormatInternal(obj any, context *commonFormat
 			context.Pop()
 		}
 		return m
-	case reflect.Slice:
-		if !isPathOverridden {
+	case reflect.Map:
+		v := reflect.ValueOf(obj)
+		if v.IsNil() && context.overrides == nil {
 			return nil
 		}
+		m := map[string]any{}
+		mapField := context.Peek()
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := MapKeyString(iter.Key())
+			if err != nil {
+				log.Printf("Field '%s' will not be saved.  %v", path, err)
+				return nil
+			}
+			context.Push(mapKeyField(mapField, t, key))
+			c := a.toCommonFormatInternal(iter.Value().Interface(), context)
+			if c != nil {
+				m[key] = c
+			}
+			context.Pop()
+		}
+		if context.overrides != nil {
+			// keys the child removed from its parent's map
+			for _, key := range removedMapKeys(context.overrides, path, m) {
+				m[key] = nil
+			}
+			if v.IsNil() && len(m) == 0 {
+				return nil
+			}
+		}
+		return m
+	case reflect.Slice:
 		v := reflect.ValueOf(obj)
+		if !isPathOverridden {
+			if t.Elem().Kind() == reflect.Uint8 {
+				return nil
+			}
+			// the child may still override some of the elements
+			return a.sliceDiffFromOverrides(v, context)
+		}
 		l := v.Len()
 		if l > 0 && v.Index(0).Kind() == reflect.Uint8 {
 			// byte slices are uuencoded into a string (because the json package does it that way)
@@ -207,10 +311,18 @@ func (a *assetManagerImpl) toCommonFormatInternal(obj any, context *commonFormat
 			encoded := base64.StdEncoding.EncodeToString(bytes)
 			return encoded
 		} else {
+			sliceField := context.Peek()
 			s := make([]any, v.Len())
 			for i := 0; i < v.Len(); i++ {
 				index := v.Index(i)
+				context.Push(sliceElementField(sliceField, t, strconv.Itoa(i)))
 				s[i] = a.toCommonFormatInternal(index.Interface(), context)
+				context.Pop()
+			}
+			if sliceField != nil {
+				if _, sorted := GetFlatTag(sliceField, "sort"); sorted {
+					sortCommonList(s, sliceKey(sliceField))
+				}
 			}
 			return s
 		}
@@ -227,7 +339,7 @@ func jsp(pref string, o any) {
 	fmt.Printf("%#v\n", o)
 }
 
-func (a *assetManagerImpl) findDiffsFromParent(parent, child any) any {
+func (a *Manager) findDiffsFromParent(parent, child any) any {
 	if child == nil {
 		return nil
 	}
@@ -240,20 +352,34 @@ func (a *assetManagerImpl) findDiffsFromParent(parent, child any) any {
 	}
 	childConcrete := reflect.ValueOf(child).Elem().Interface()
 	childJson := a.toCommonFormat(childConcrete)
-	return findDiffsFromParentCommonFormat(parentJson, childJson)
+	return a.findDiffsFromParentCommonFormat(parentJson, childJson, reflect.TypeOf(childConcrete), nil)
 }
 
 // findDiffsFromParentCommonFormat expects to take output from toCommonFormat
 // The input objects are compared recursively.  If parent and child are the same
-// then nil is returned.
-// Maps recursively apply this function to each key.
+// then nil is returned.  t is the type the child was saved from, and sf the
+// struct field that holds it, they are nil when not known.
+// Maps recursively apply this function to each key, the keys of the
+// parent's map that the child does not have are nil.
+// Slices are diffed per element (see sliceDiff).
 // The results is a map that contains only key/value pairs where the child
 // is different from the Parent.  In the degenerate case, a copy of child will
 // be returned (in Common format).
-func findDiffsFromParentCommonFormat(parent, child any) any {
+func (a *Manager) findDiffsFromParentCommonFormat(parent, child any, t reflect.Type, sf *reflect.StructField) any {
 	//jsp("Parent ----", parent)
 	//jsp("Child ----", child)
 
+	if s := a.serializerFor(t); s != nil {
+		// serialized values are compared whole
+		if parent == nil {
+			parent = s.zero(t)
+		}
+		if reflect.DeepEqual(parent, child) {
+			return nil
+		}
+		return child
+	}
+
 	childType := reflect.TypeOf(child)
 	parentValue := reflect.ValueOf(parent)
 	childValue := reflect.ValueOf(child)
@@ -282,7 +408,17 @@ func findDiffsFromParentCommonFormat(parent, child any) any {
 		if parent == child {
 			return nil
 		}
+	case reflect.Pointer:
+		// references and inline containers are compared by value
+		if reflect.DeepEqual(parent, child) {
+			return nil
+		}
 	case reflect.Slice, reflect.Array:
+		parentList, parentIsList := parent.([]any)
+		childList, childIsList := child.([]any)
+		if parentIsList && childIsList && t != nil && t.Kind() == reflect.Slice {
+			return a.diffSlice(parentList, childList, t, sf)
+		}
 		if childValue.IsZero() || childValue.Len() == 0 {
 			return nil
 		}
@@ -296,7 +432,7 @@ func findDiffsFromParentCommonFormat(parent, child any) any {
 		for i := 0; i < childValue.Len(); i++ {
 			pv := parentValue.Index(i).Interface()
 			cv := childValue.Index(i).Interface()
-			diff := findDiffsFromParentCommonFormat(pv, cv)
+			diff := a.findDiffsFromParentCommonFormat(pv, cv, nil, nil)
 			if diff != nil {
 				return child
 			}
@@ -315,11 +451,30 @@ func findDiffsFromParentCommonFormat(parent, child any) any {
 				}
 			}
 
-			diffs := findDiffsFromParentCommonFormat(parentMapValue, v.Interface())
+			var valueType reflect.Type
+			valueField := sf
+			if t != nil && t.Kind() == reflect.Struct {
+				valueType, valueField = nil, nil
+				if field, ok := t.FieldByName(k.String()); ok {
+					valueType, valueField = field.Type, &field
+				}
+			} else if t != nil && t.Kind() == reflect.Map {
+				valueType = t.Elem()
+			}
+			diffs := a.findDiffsFromParentCommonFormat(parentMapValue, v.Interface(), valueType, valueField)
 			if diffs != nil {
 				ret.SetMapIndex(k, reflect.ValueOf(diffs))
 			}
 		}
+		if t != nil && t.Kind() == reflect.Map && parentValue.Kind() == reflect.Map {
+			// the child removed these keys
+			parentIter := parentValue.MapRange()
+			for parentIter.Next() {
+				if k := parentIter.Key(); !childValue.MapIndex(k).IsValid() {
+					ret.SetMapIndex(k, reflect.Zero(childType.Elem()))
+				}
+			}
+		}
 		if len(ret.MapKeys()) == 0 {
 			// if there are no keys, we found no diffs so can ignore this struct when saving the child
 			return nil
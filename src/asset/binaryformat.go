@@ -0,0 +1,499 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// The binary format is a compact encoding of the same data that is saved as
+// json.  It is intended for shipped builds, the editor always writes json.
+// A binary file is
+//
+//	binaryMagic
+//	uvarint count of strings, then each string as (uvarint length, bytes)
+//	a single value
+//
+// Every value starts with a one byte tag.  Strings and map keys are stored
+// once in the string table and referred to by index.  All numbers load as
+// float64, exactly as they do from json.
+var binaryMagic = []byte("FLATBIN\x01")
+
+const (
+	binaryTagNil byte = iota
+	binaryTagFalse
+	binaryTagTrue
+	binaryTagInt   // zigzag varint, for whole numbers
+	binaryTagFloat // 8 bytes, little endian
+	binaryTagString
+	binaryTagBytes
+	binaryTagList
+	binaryTagMap
+)
+
+// SaveFormat selects the encoding that Save writes
+type SaveFormat int
+
+const (
+	SaveFormatJSON SaveFormat = iota
+	SaveFormatBinary
+)
+
+// isBinaryFormat returns true if data starts with the binary header
+func isBinaryFormat(data []byte) bool {
+	return bytes.HasPrefix(data, binaryMagic)
+}
+
+// decodeContainer decodes an asset file in either json or binary format.
+// The returned Inner is in Common format.
+func decodeContainer(data []byte) (*onDiskSaveFormat, error) {
+	if isBinaryFormat(data) {
+		return decodeBinaryContainer(data)
+	}
+
+	container := onDiskLoadFormat{}
+	err := json.Unmarshal(data, &container)
+	if err != nil {
+		return nil, err
+	}
+	var commonFormat any
+	if len(container.Inner) > 0 {
+		err = json.Unmarshal(container.Inner, &commonFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &onDiskSaveFormat{
+		Type:     container.Type,
+		ID:       container.ID,
+		Version:  container.Version,
+		Parent:   container.Parent,
+		ParentID: container.ParentID,
+		Inner:    commonFormat,
+	}, nil
+}
+
+type binaryEncoder struct {
+	strings map[string]uint64
+	table   []string
+	body    bytes.Buffer
+}
+
+// encodeBinary encodes v, which may be any mix of Common format values
+// and on-disk container structs.
+func encodeBinary(v any) ([]byte, error) {
+	e := &binaryEncoder{strings: map[string]uint64{}}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Write(binaryMagic)
+	e.writeUvarint(&out, uint64(len(e.table)))
+	for _, s := range e.table {
+		e.writeUvarint(&out, uint64(len(s)))
+		out.WriteString(s)
+	}
+	out.Write(e.body.Bytes())
+	return out.Bytes(), nil
+}
+
+func (e *binaryEncoder) writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	buf.Write(b[:n])
+}
+
+func (e *binaryEncoder) stringIndex(s string) uint64 {
+	if index, ok := e.strings[s]; ok {
+		return index
+	}
+	index := uint64(len(e.table))
+	e.strings[s] = index
+	e.table = append(e.table, s)
+	return index
+}
+
+func (e *binaryEncoder) writeString(s string) {
+	e.writeUvarint(&e.body, e.stringIndex(s))
+}
+
+func (e *binaryEncoder) writeFloat(f float64) {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		e.body.WriteByte(binaryTagInt)
+		var b [binary.MaxVarintLen64]byte
+		n := binary.PutVarint(b[:], int64(f))
+		e.body.Write(b[:n])
+		return
+	}
+	e.body.WriteByte(binaryTagFloat)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	e.body.Write(b[:])
+}
+
+func (e *binaryEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.body.WriteByte(binaryTagNil)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.body.WriteByte(binaryTagNil)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.body.WriteByte(binaryTagTrue)
+		} else {
+			e.body.WriteByte(binaryTagFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeFloat(float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeFloat(float64(v.Uint()))
+	case reflect.Float32:
+		// match json, which writes the shortest float32 representation
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, 32), 64)
+		e.writeFloat(f)
+	case reflect.Float64:
+		e.writeFloat(v.Float())
+	case reflect.String:
+		e.body.WriteByte(binaryTagString)
+		e.writeString(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			var data []byte
+			if v.Kind() == reflect.Slice {
+				data = v.Bytes()
+			} else {
+				data = make([]byte, v.Len())
+				reflect.Copy(reflect.ValueOf(data), v)
+			}
+			e.body.WriteByte(binaryTagBytes)
+			e.writeUvarint(&e.body, uint64(len(data)))
+			e.body.Write(data)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.body.WriteByte(binaryTagNil)
+			return nil
+		}
+		e.body.WriteByte(binaryTagList)
+		e.writeUvarint(&e.body, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("binary format only supports string map keys, not %v", v.Type())
+		}
+		if v.IsNil() {
+			e.body.WriteByte(binaryTagNil)
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		e.body.WriteByte(binaryTagMap)
+		e.writeUvarint(&e.body, uint64(len(keys)))
+		for _, key := range keys {
+			e.writeString(key.String())
+			if err := e.encode(v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		// on-disk containers are the only structs in Common format, they are
+		// written as maps of their exported fields, like json does
+		t := v.Type()
+		var fields []int
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				fields = append(fields, i)
+			}
+		}
+		e.body.WriteByte(binaryTagMap)
+		e.writeUvarint(&e.body, uint64(len(fields)))
+		for _, i := range fields {
+			e.writeString(t.Field(i).Name)
+			if err := e.encode(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unable to encode %v in binary format", v.Type())
+	}
+	return nil
+}
+
+type binaryDecoder struct {
+	data  []byte
+	pos   int
+	table []string
+}
+
+var errBinaryTruncated = fmt.Errorf("binary asset is truncated")
+
+func decodeBinary(data []byte) (any, error) {
+	if !isBinaryFormat(data) {
+		return nil, fmt.Errorf("data is not in binary asset format")
+	}
+	d := &binaryDecoder{data: data, pos: len(binaryMagic)}
+	count, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		l, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.bytes(l)
+		if err != nil {
+			return nil, err
+		}
+		d.table = append(d.table, string(s))
+	}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("binary asset has %d trailing bytes", len(d.data)-d.pos)
+	}
+	return v, nil
+}
+
+func decodeBinaryContainer(data []byte) (*onDiskSaveFormat, error) {
+	v, err := decodeBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("binary asset does not contain an asset container")
+	}
+	return containerFromCommonFormat(m), nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errBinaryTruncated
+	}
+	d.pos += n
+	return v, nil
+}
+
+// count reads the number of items that follow.  Every item takes at least
+// one byte, so a count larger than the bytes left comes from a truncated
+// or corrupt file, and is not used to size an allocation.
+func (d *binaryDecoder) count() (uint64, error) {
+	l, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if l > uint64(len(d.data)-d.pos) {
+		return 0, errBinaryTruncated
+	}
+	return l, nil
+}
+
+func (d *binaryDecoder) bytes(l uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < l {
+		return nil, errBinaryTruncated
+	}
+	b := d.data[d.pos : d.pos+int(l)]
+	d.pos += int(l)
+	return b, nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	index, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if index >= uint64(len(d.table)) {
+		return "", fmt.Errorf("binary asset string index %d out of range", index)
+	}
+	return d.table[index], nil
+}
+
+func (d *binaryDecoder) decode() (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, errBinaryTruncated
+	}
+	tag := d.data[d.pos]
+	d.pos++
+	switch tag {
+	case binaryTagNil:
+		return nil, nil
+	case binaryTagFalse:
+		return false, nil
+	case binaryTagTrue:
+		return true, nil
+	case binaryTagInt:
+		v, n := binary.Varint(d.data[d.pos:])
+		if n <= 0 {
+			return nil, errBinaryTruncated
+		}
+		d.pos += n
+		return float64(v), nil
+	case binaryTagFloat:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case binaryTagString:
+		return d.string()
+	case binaryTagBytes:
+		l, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(l)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Clone(b), nil
+	case binaryTagList:
+		l, err := d.count()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, 0, l)
+		for i := uint64(0); i < l; i++ {
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case binaryTagMap:
+		l, err := d.count()
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, l)
+		for i := uint64(0); i < l; i++ {
+			k, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown binary asset tag %d", tag)
+}
+
+// withRawBytes returns a copy of container where the base64 strings that
+// Common format uses for byte slices, and the hex lines of canonical json,
+// are replaced by the raw bytes, so
+// that the binary format can store them compactly.  The registered asset
+// types are used to find the byte slices, unregistered types are
+// returned unchanged.
+func (a *Manager) withRawBytes(container *onDiskSaveFormat) *onDiskSaveFormat {
+	desc := a.descriptorForTypeName(container.Type)
+	if desc == nil {
+		return container
+	}
+	withBytes := *container
+	withBytes.Inner = a.withRawBytesForType(desc.Type, container.Inner)
+	return &withBytes
+}
+
+func (a *Manager) withRawBytesForType(t reflect.Type, v any) any {
+	if v == nil || a.serializerFor(t) != nil {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		// inline assets are the only pointers that contain values
+		switch inline := v.(type) {
+		case *onDiskSaveFormat:
+			return a.withRawBytes(inline)
+		case map[string]any:
+			if _, hasInner := inline["Inner"]; hasInner {
+				return a.withRawBytes(containerFromCommonFormat(inline))
+			}
+		}
+	case reflect.Struct:
+		if m, ok := v.(map[string]any); ok {
+			result := make(map[string]any, len(m))
+			for k, fieldValue := range m {
+				if sf, ok := t.FieldByName(k); ok {
+					result[k] = a.withRawBytesForType(sf.Type, fieldValue)
+				} else {
+					result[k] = fieldValue
+				}
+			}
+			return result
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]any); ok {
+			result := make(map[string]any, len(m))
+			for k, itemValue := range m {
+				result[k] = a.withRawBytesForType(t.Elem(), itemValue)
+			}
+			return result
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if decoded, err := decodeSavedBytes(v); err == nil {
+				return decoded
+			}
+			return v
+		}
+		if list, ok := v.([]any); ok {
+			result := make([]any, len(list))
+			for i, item := range list {
+				result[i] = a.withRawBytesForType(t.Elem(), item)
+			}
+			return result
+		}
+		if diff, ok := asSliceDiff(v); ok && diff.Elements != nil {
+			// a child's changes to its parent's elements
+			result := make(map[string]any, len(diff.Elements))
+			for id, item := range diff.Elements {
+				result[id] = a.withRawBytesForType(t.Elem(), item)
+			}
+			diff.Elements = result
+			return diff.commonFormat()
+		}
+	}
+	return v
+}
+
+// containerFromCommonFormat converts an inline container that has been
+// decoded into a map back to the container struct
+func containerFromCommonFormat(m map[string]any) *onDiskSaveFormat {
+	typeName, _ := m["Type"].(string)
+	id, _ := m["ID"].(string)
+	parent, _ := m["Parent"].(string)
+	parentID, _ := m["ParentID"].(string)
+	version, _ := m["Version"].(float64)
+	return &onDiskSaveFormat{
+		Type:     typeName,
+		ID:       id,
+		Version:  int(version),
+		Parent:   Path(parent),
+		ParentID: parentID,
+		Inner:    m["Inner"],
+	}
+}
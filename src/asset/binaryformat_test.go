@@ -0,0 +1,122 @@
+package asset_test
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type binaryLeaf struct {
+	Name string
+	Data []byte
+}
+
+type binaryRoundTrip struct {
+	Name     string
+	Int      int
+	Neg      int64
+	Small    uint8
+	Flt32    float32
+	Flt64    float64
+	Yes      bool
+	Raw      []byte
+	Ints     []int
+	Array    [3]float32
+	Nested   binaryLeaf
+	Leaves   []binaryLeaf
+	Ref      *binaryLeaf
+	Inline   *binaryLeaf `flat:"inline"`
+	Iface    any         `flat:"inline"`
+	NilIface any         `flat:"inline"`
+	Path     asset.Path
+}
+
+func newRoundTripManager(readFS fs.FS, writeFS asset.WriteableFileSystem) *asset.Manager {
+	m := asset.NewManager()
+	m.SetEditorMode()
+	m.RegisterAsset(binaryLeaf{})
+	m.RegisterAsset(binaryRoundTrip{})
+	if readFS != nil {
+		m.RegisterFileSystem(readFS, 0)
+	}
+	if writeFS != nil {
+		m.RegisterWritableFileSystem(writeFS)
+	}
+	return m
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	jsonFS := newWriteFS()
+	m := newRoundTripManager(jsonFS.fs, jsonFS)
+
+	leaf := &binaryLeaf{Name: "leaf", Data: []byte{0, 1, 2, 255}}
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	parent := &binaryLeaf{Name: "parent", Data: []byte("parent data")}
+	assert.NoError(t, m.Save("parent.json", parent))
+
+	child := &binaryLeaf{Name: "parent", Data: []byte("child data")}
+	m.SetParent(child, parent)
+
+	toSave := &binaryRoundTrip{
+		Name:   "round trip",
+		Int:    42,
+		Neg:    -1 << 40,
+		Small:  200,
+		Flt32:  0.1,
+		Flt64:  1.0 / 3.0,
+		Yes:    true,
+		Raw:    []byte("raw bytes \x00\x01"),
+		Ints:   []int{1, -2, 3},
+		Array:  [3]float32{1.5, -2.25, 3},
+		Nested: binaryLeaf{Name: "nested", Data: []byte{9, 8, 7}},
+		Leaves: []binaryLeaf{{Name: "a", Data: []byte{2}}, {Name: "b", Data: []byte{1}}},
+		Ref:    leaf,
+		Inline: child,
+		Iface:  &binaryLeaf{Name: "iface"},
+		Path:   "some/file.png",
+	}
+	assert.NoError(t, m.Save("roundtrip.json", toSave))
+
+	// cook the json content to binary
+	binaryFS := newWriteFS()
+	assert.NoError(t, m.Cook(binaryFS))
+	cooked, err := fs.ReadFile(binaryFS.fs, "roundtrip.json")
+	assert.NoError(t, err)
+	original, err := fs.ReadFile(jsonFS.fs, "roundtrip.json")
+	assert.NoError(t, err)
+	assert.Less(t, len(cooked), len(original), "binary should be smaller than json")
+
+	fromJSON, err := newRoundTripManager(jsonFS.fs, nil).Load("roundtrip.json")
+	assert.NoError(t, err)
+	fromBinary, err := newRoundTripManager(binaryFS.fs, nil).Load("roundtrip.json")
+	assert.NoError(t, err)
+	assert.Equal(t, fromJSON, fromBinary)
+	assert.Equal(t, toSave, fromBinary)
+
+	// Save can also write binary directly
+	savedFS := newWriteFS()
+	binarySaver := newRoundTripManager(jsonFS.fs, savedFS)
+	binarySaver.SetSaveFormat(asset.SaveFormatBinary)
+	for _, path := range []asset.Path{"leaf.json", "parent.json", "roundtrip.json"} {
+		a, err := binarySaver.Load(path)
+		assert.NoError(t, err)
+		assert.NoError(t, binarySaver.Save(path, a))
+	}
+	fromSaved, err := newRoundTripManager(savedFS.fs, nil).Load("roundtrip.json")
+	assert.NoError(t, err)
+	assert.Equal(t, fromJSON, fromSaved)
+}
+
+func TestBinaryFilterFilesByType(t *testing.T) {
+	jsonFS := newWriteFS()
+	m := newRoundTripManager(jsonFS.fs, jsonFS)
+	m.SetSaveFormat(asset.SaveFormatBinary)
+	assert.NoError(t, m.Save("leaf.json", &binaryLeaf{Name: "leaf"}))
+
+	files, err := m.FilterFilesByType(reflect.TypeOf(binaryLeaf{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"leaf.json"}, files)
+}
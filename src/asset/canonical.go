@@ -0,0 +1,442 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Assets are saved as canonical json, so that saving an asset that did not
+// change writes exactly the same bytes, and a change to an asset is a small
+// diff.  Canonical json is
+//   - struct fields in the order they are declared, fields that are no
+//     longer in the struct after them, sorted
+//   - map keys sorted, numerically for integer keys
+//   - numbers with the shortest text that loads as the same value, for
+//     float32 fields the shortest float32 text, so that 0.1 is not saved as
+//     0.10000000149011612
+//   - byte slices as a list of hex strings, bytesPerLine bytes per line,
+//     rather than a single base64 string, so that they diff by line
+//
+// The registered asset types are used to order the fields, unregistered
+// types fall back to sorted keys.
+
+// bytesPerLine is how many bytes each hex string of a saved byte slice holds
+const bytesPerLine = 32
+
+// encodeContainer encodes container in the given format, json is canonical
+func (a *Manager) encodeContainer(container *onDiskSaveFormat, format SaveFormat) ([]byte, error) {
+	switch format {
+	case SaveFormatJSON:
+		return json.MarshalIndent(a.canonicalContainer(container), "", "  ")
+	case SaveFormatBinary:
+		return encodeBinary(container)
+	}
+	return nil, fmt.Errorf("unknown save format %d", format)
+}
+
+// Resave loads every asset that the Manager can read and saves it again
+// with the writable file system, keeping the format (json or binary) it
+// was saved in.  Files that were saved before json was canonical, or by
+// hand, change, and their paths are returned.  Assets of unregistered
+// types are skipped, and an asset that fails to load stops the Resave
+// rather than lose the values that did not load.
+func (a *Manager) Resave() ([]Path, error) {
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	a.mu.RUnlock()
+	if writeFS == nil {
+		return nil, fmt.Errorf("Resave needs a writable file system")
+	}
+
+	var resaved []Path
+	err := a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := a.ReadFile(Path(path))
+		if err != nil {
+			return err
+		}
+		container, err := decodeContainer(data)
+		if err != nil || container.Type == "" || a.descriptorForTypeName(container.Type) == nil {
+			// not an asset, or not one that can be loaded
+			return nil
+		}
+		loaded, err := a.Load(Path(path))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		container, err = a.toDiskFormat(loaded)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		container.ID = a.idForSave(loaded, Path(path))
+		format := SaveFormatJSON
+		if isBinaryFormat(data) {
+			format = SaveFormatBinary
+			container = a.withRawBytes(container)
+		}
+		encoded, err := a.encodeContainer(container, format)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(encoded, data) {
+			return nil
+		}
+		if err := writeFS.WriteFile(Path(path), encoded); err != nil {
+			return err
+		}
+		a.wrote(Path(path))
+		resaved = append(resaved, Path(path))
+		return nil
+	})
+
+	a.mu.Lock()
+	a.dependencies = nil
+	a.mu.Unlock()
+	return resaved, err
+}
+
+// orderedMap is a json object whose keys are written in the order of keys
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	m.keys = append(m.keys, key)
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// canonicalContainer orders a saved (or inline) asset container
+func (a *Manager) canonicalContainer(container *onDiskSaveFormat) *orderedMap {
+	m := &orderedMap{values: map[string]any{}}
+	m.set("Type", container.Type)
+	if container.ID != "" {
+		m.set("ID", container.ID)
+	}
+	if container.Version != 0 {
+		m.set("Version", container.Version)
+	}
+	m.set("Parent", container.Parent)
+	if container.ParentID != "" {
+		m.set("ParentID", container.ParentID)
+	}
+	var t reflect.Type
+	if desc := a.descriptorForTypeName(container.Type); desc != nil {
+		t = desc.Type
+	}
+	m.set("Inner", a.canonical(t, container.Inner))
+	return m
+}
+
+// canonicalContainerMap orders an inline asset container that was read
+// from disk, and is still a map in Common format, like canonicalContainer
+func (a *Manager) canonicalContainerMap(m map[string]any) *orderedMap {
+	ordered := &orderedMap{values: map[string]any{}}
+	for _, key := range []string{"Type", "ID", "Version", "Parent", "ParentID"} {
+		if value, found := m[key]; found {
+			ordered.set(key, a.canonicalUntyped(value))
+		}
+	}
+	for _, key := range sortedMapKeys(m) {
+		if _, written := ordered.values[key]; !written && key != "Inner" {
+			ordered.set(key, a.canonicalUntyped(m[key]))
+		}
+	}
+	var t reflect.Type
+	typeName, _ := m["Type"].(string)
+	if desc := a.descriptorForTypeName(typeName); desc != nil {
+		t = desc.Type
+	}
+	ordered.set("Inner", a.canonical(t, m["Inner"]))
+	return ordered
+}
+
+// canonical orders v, a value in Common format that was saved from a
+// value of type t.  t is nil when it is not known.
+func (a *Manager) canonical(t reflect.Type, v any) any {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case *onDiskSaveFormat:
+		return a.canonicalContainer(value)
+	case *assetLoadPath:
+		return canonicalReference(map[string]any{"Type": value.Type, "Path": value.Path, "ID": value.ID})
+	case float64:
+		if t != nil && t.Kind() == reflect.Float32 {
+			return float32(value)
+		}
+		return value
+	}
+
+	if t == nil || a.serializerFor(t) != nil {
+		return a.canonicalUntyped(v)
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if m, ok := v.(map[string]any); ok {
+			if _, isContainer := m["Inner"]; isContainer {
+				return a.canonicalContainerMap(m)
+			}
+			if _, isReference := m["Path"]; isReference {
+				return canonicalReference(m)
+			}
+		}
+		if t.Kind() == reflect.Pointer {
+			return a.canonical(t.Elem(), v)
+		}
+		return a.canonicalUntyped(v)
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			break
+		}
+		ordered := &orderedMap{values: map[string]any{}}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if fieldValue, found := m[sf.Name]; found && IsSavedField(&sf) {
+				ordered.set(sf.Name, a.canonical(sf.Type, fieldValue))
+			}
+		}
+		for _, key := range sortedMapKeys(m) {
+			if _, written := ordered.values[key]; !written {
+				ordered.set(key, a.canonicalUntyped(m[key]))
+			}
+		}
+		return ordered
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := sortedMapKeys(m)
+		if isIntegerKind(t.Key().Kind()) {
+			sort.SliceStable(keys, func(i, j int) bool { return idLess(keys[i], keys[j]) })
+		}
+		ordered := &orderedMap{values: map[string]any{}}
+		for _, key := range keys {
+			ordered.set(key, a.canonical(t.Elem(), m[key]))
+		}
+		return ordered
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if decoded, err := decodeSavedBytes(v); err == nil {
+				return hexLines(decoded)
+			}
+			break
+		}
+		switch list := v.(type) {
+		case []any:
+			result := make([]any, len(list))
+			for i, item := range list {
+				result[i] = a.canonical(t.Elem(), item)
+			}
+			return result
+		case map[string]any:
+			// a child's sliceDiff
+			diff, _ := asSliceDiff(list)
+			ordered := &orderedMap{values: map[string]any{}}
+			if len(diff.Elements) > 0 {
+				ids := make([]string, 0, len(diff.Elements))
+				for id := range diff.Elements {
+					ids = append(ids, id)
+				}
+				sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+				elements := &orderedMap{values: map[string]any{}}
+				for _, id := range ids {
+					elements.set(id, a.canonical(t.Elem(), diff.Elements[id]))
+				}
+				ordered.set("Elements", elements)
+			}
+			if len(diff.Removed) > 0 {
+				ordered.set("Removed", diff.Removed)
+			}
+			if diff.Order != nil {
+				ordered.set("Order", diff.Order)
+			}
+			return ordered
+		}
+	}
+	return a.canonicalUntyped(v)
+}
+
+// canonicalUntyped orders a value whose type is not known
+func (a *Manager) canonicalUntyped(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		if _, isContainer := value["Inner"]; isContainer {
+			if _, hasType := value["Type"]; hasType {
+				return a.canonicalContainerMap(value)
+			}
+		}
+		ordered := &orderedMap{values: map[string]any{}}
+		for _, key := range sortedMapKeys(value) {
+			ordered.set(key, a.canonicalUntyped(value[key]))
+		}
+		return ordered
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			result[i] = a.canonicalUntyped(item)
+		}
+		return result
+	}
+	return v
+}
+
+// canonicalReference orders a saved reference to another asset like
+// assetLoadPath declares its fields
+func canonicalReference(m map[string]any) *orderedMap {
+	ordered := &orderedMap{values: map[string]any{}}
+	for _, key := range []string{"Type", "Path", "ID"} {
+		if value, found := m[key]; found && !(key == "ID" && value == "") {
+			ordered.set(key, value)
+		}
+	}
+	for _, key := range sortedMapKeys(m) {
+		if _, written := ordered.values[key]; !written && key != "ID" {
+			ordered.set(key, m[key])
+		}
+	}
+	return ordered
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// hexLines is the canonical json for a byte slice
+func hexLines(data []byte) []any {
+	lines := []any{}
+	for len(data) > 0 {
+		n := bytesPerLine
+		if n > len(data) {
+			n = len(data)
+		}
+		lines = append(lines, hex.EncodeToString(data[:n]))
+		data = data[n:]
+	}
+	return lines
+}
+
+// decodeSavedBytes reads a byte slice saved in any of the formats that
+// byte slices have been saved in.  These are a base64 string (Common
+// format, and json before it was canonical), a list of hex strings
+// (canonical json), and raw bytes (the binary format).
+func decodeSavedBytes(v any) ([]byte, error) {
+	switch saved := v.(type) {
+	case []byte:
+		return saved, nil
+	case string:
+		return base64.StdEncoding.DecodeString(saved)
+	case []any:
+		var decoded []byte
+		for _, line := range saved {
+			s, ok := line.(string)
+			if !ok {
+				return nil, fmt.Errorf("cannot load %v as bytes, it is not a hex string", line)
+			}
+			b, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			decoded = append(decoded, b...)
+		}
+		if decoded == nil {
+			decoded = []byte{}
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("cannot load %v as bytes", v)
+}
+
+// sortCommonList sorts a list in Common format for a `flat:"sort"` slice.
+// Elements are sorted by their key field when the slice has a
+// `flat:"key:Name"` tag, numbers numerically, strings as strings, and
+// anything else by its json.
+func sortCommonList(list []any, key string) {
+	if ids, ok := commonElementIDs(list, key); ok && key != "" {
+		byID := make(map[string]any, len(list))
+		for i, id := range ids {
+			byID[id] = list[i]
+		}
+		sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+		for i, id := range ids {
+			list[i] = byID[id]
+		}
+		return
+	}
+	sort.SliceStable(list, func(i, j int) bool { return commonLess(list[i], list[j]) })
+}
+
+func commonLess(a, b any) bool {
+	an, aIsNumber := asNumber(a)
+	bn, bIsNumber := asNumber(b)
+	if aIsNumber && bIsNumber {
+		return an < bn
+	}
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString && bIsString {
+		return as < bs
+	}
+	aj, _ := json.Marshal(a)
+	bj, _ := json.Marshal(b)
+	return strings.Compare(string(aj), string(bj)) < 0
+}
+
+// asNumber returns v as a float64 if it is a number.  Common format holds
+// the numbers of a saved struct as their Go type, and loaded ones as float64.
+func asNumber(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		return 0, false
+	case rv.CanFloat():
+		return rv.Float(), true
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package asset_test
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type canonicalWeapon struct {
+	Name   string
+	Damage float64
+}
+
+type canonicalShip struct {
+	Zebra   string
+	Apple   string
+	Scale   float32
+	Mass    float64
+	Raw     []byte
+	Lookup  map[int]string
+	Tags    []string          `flat:"sort"`
+	Weapons []canonicalWeapon `flat:"key:Name;sort"`
+}
+
+type canonicalDefaults struct {
+	Name   string
+	Health float64
+	Lives  int
+}
+
+func (c *canonicalDefaults) DefaultInitialize() {
+	c.Health = 100
+	c.Lives = 3
+}
+
+func TestCanonicalSave(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, canonicalShip{}, canonicalDefaults{})
+	ship := &canonicalShip{
+		Zebra:  "z",
+		Apple:  "a",
+		Scale:  0.1,
+		Mass:   1e21,
+		Raw:    []byte("0123456789abcdef0123456789abcdef0123"),
+		Lookup: map[int]string{10: "ten", 9: "nine", 100: "hundred"},
+		Tags:   []string{"b", "c", "a"},
+		Weapons: []canonicalWeapon{
+			{Name: "laser", Damage: 2},
+			{Name: "cannon", Damage: 5},
+		},
+	}
+	assert.NoError(t, m.Save("ship.json", ship))
+
+	data, err := fs.ReadFile(wfs.fs, "ship.json")
+	assert.NoError(t, err)
+	expected := fmt.Sprintf(`{
+  "Type": "github.com/bradbev/flatland/src/asset_test.canonicalShip",
+  "ID": "%s",
+  "Parent": "",
+  "Inner": {
+    "Zebra": "z",
+    "Apple": "a",
+    "Scale": 0.1,
+    "Mass": 1e+21,
+    "Raw": [
+      "3031323334353637383961626364656630313233343536373839616263646566",
+      "30313233"
+    ],
+    "Lookup": {
+      "9": "nine",
+      "10": "ten",
+      "100": "hundred"
+    },
+    "Tags": [
+      "a",
+      "b",
+      "c"
+    ],
+    "Weapons": [
+      {
+        "Name": "cannon",
+        "Damage": 5
+      },
+      {
+        "Name": "laser",
+        "Damage": 2
+      }
+    ]
+  }
+}`, m.AssetID(ship))
+	assert.Equal(t, expected, string(data))
+
+	// sorting only changes the saved order
+	assert.Equal(t, []string{"b", "c", "a"}, ship.Tags)
+
+	// loading and saving again writes the same bytes
+	fresh := newTestManager(wfs, canonicalShip{})
+	loaded, err := fresh.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, ship.Raw, loaded.(*canonicalShip).Raw)
+	assert.Equal(t, float32(0.1), loaded.(*canonicalShip).Scale)
+	assert.NoError(t, fresh.Save("ship.json", loaded))
+	resaved, err := fs.ReadFile(wfs.fs, "ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(resaved))
+}
+
+func TestLegacyBytesLoad(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, canonicalShip{}, canonicalDefaults{})
+	// byte slices were saved as base64 before json was canonical
+	wfs.WriteFile("old.json", []byte(`{
+  "Type": "github.com/bradbev/flatland/src/asset_test.canonicalShip",
+  "Parent": "",
+  "Inner": {"Raw": "cmF3"}
+}`))
+	loaded, err := m.Load("old.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("raw"), loaded.(*canonicalShip).Raw)
+}
+
+func TestOmitDefaults(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, canonicalShip{}, canonicalDefaults{})
+	unchanged := asset.New[canonicalDefaults]()
+	unchanged.Name = "unchanged"
+	changed := asset.New[canonicalDefaults]()
+	changed.Name = "changed"
+	changed.Health = 0
+	changed.Lives = 5
+
+	assert.NoError(t, m.Save("without.json", unchanged))
+	inner := readContainer(t, wfs, "without.json")["Inner"].(js)
+	assert.Equal(t, js{"Name": "unchanged", "Health": float64(100), "Lives": float64(3)}, inner)
+
+	m.SetOmitDefaults(true)
+	assert.NoError(t, m.Save("unchanged.json", unchanged))
+	assert.NoError(t, m.Save("changed.json", changed))
+	inner = readContainer(t, wfs, "unchanged.json")["Inner"].(js)
+	assert.Equal(t, js{"Name": "unchanged"}, inner)
+	inner = readContainer(t, wfs, "changed.json")["Inner"].(js)
+	assert.Equal(t, js{"Name": "changed", "Health": float64(0), "Lives": float64(5)}, inner)
+
+	fresh := asset.NewManager()
+	fresh.RegisterAsset(canonicalDefaults{})
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	loaded, err := fresh.Load("unchanged.json")
+	assert.NoError(t, err)
+	assert.Equal(t, unchanged, loaded)
+	loaded, err = fresh.Load("changed.json")
+	assert.NoError(t, err)
+	assert.Equal(t, changed, loaded)
+}
+
+func TestResave(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, canonicalShip{}, canonicalDefaults{})
+	wfs.WriteFile("ship.json", []byte(`{"Inner":{"Apple":"a","Zebra":"z"},"Parent":"","Type":"github.com/bradbev/flatland/src/asset_test.canonicalShip"}`))
+	wfs.WriteFile("unknown.json", []byte(`{"Type":"not.registered","Inner":{}}`))
+
+	resaved, err := m.Resave()
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"ship.json"}, resaved)
+	inner := readContainer(t, wfs, "ship.json")["Inner"].(js)
+	assert.Equal(t, js{"Zebra": "z", "Apple": "a"}, inner)
+
+	resaved, err = m.Resave()
+	assert.NoError(t, err)
+	assert.Empty(t, resaved)
+}
@@ -0,0 +1,466 @@
+package asset
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Problem is something wrong with an asset file that Check found.
+type Problem struct {
+	// Path is the asset file that has the problem
+	Path Path
+	// Field is the dotted path to the field with the problem, for example
+	// "Ship.Images[2]".  It is empty when the problem is with the whole asset.
+	Field   string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Field, p.Message)
+}
+
+// Check examines every asset the Manager can read and returns the
+// problems that would otherwise show up as missing or default values in
+// game.  It finds
+//   - files that cannot be decoded, and Types that are not registered
+//   - missing parents, parents of the wrong type and cyclic parent chains
+//   - references to assets that are missing or of the wrong type
+//   - assets that have the same ID, usually because a file was copied
+//   - asset.Path fields that name missing files, or files that do not match
+//     the field's filter tag
+//   - saved fields that the type no longer has
+//   - assets that fail to load, or that have pointers which will not be saved
+//
+// Check loads the assets it examines, so use a Manager dedicated to checking.
+func (a *Manager) Check() []Problem {
+	var checkers []*checker
+	a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		checkers = append(checkers, a.checkFile(Path(path)))
+		return nil
+	})
+	byPath := map[Path]*checker{}
+	for _, c := range checkers {
+		byPath[c.path] = c
+	}
+	index := a.dependencyIndex()
+	for id, duplicates := range index.duplicateIDs {
+		for _, duplicate := range duplicates {
+			if c := byPath[duplicate]; c != nil {
+				c.report("", "has the same ID as %s", index.ids[id])
+			}
+		}
+	}
+
+	// loading an asset that can reach a cycle fails, so find the cycles
+	// before loading anything
+	loads := map[Path][]Path{}
+	for _, c := range checkers {
+		loads[c.path] = c.loads
+	}
+	blocked := map[Path]bool{}
+	reported := map[string]bool{}
+	for _, c := range checkers {
+		cycle := findCycle(c.path, loads, blocked)
+		if cycle == nil {
+			continue
+		}
+		members := slices.Clone(cycle[:len(cycle)-1])
+		slices.Sort(members)
+		if key := strings.Join(members, ","); !reported[key] {
+			reported[key] = true
+			byPath[Path(cycle[0])].report("", "cyclic references %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	var problems []Problem
+	for _, c := range checkers {
+		if c.loadable && !blocked[c.path] {
+			c.checkLoad()
+		}
+		problems = append(problems, c.problems...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Field < problems[j].Field
+	})
+	return problems
+}
+
+// findCycle looks for a cycle reachable from start through loads.  Every
+// path that can reach a cycle is added to blocked.  The cycle is returned
+// starting and ending at the first repeated path, or nil.
+func findCycle(start Path, loads map[Path][]Path, blocked map[Path]bool) []string {
+	var stack []Path
+	onStack := map[Path]bool{}
+	done := map[Path]bool{}
+	var cycle []string
+	var visit func(p Path) bool
+	visit = func(p Path) bool {
+		if onStack[p] {
+			i := slices.Index(stack, p)
+			for _, q := range stack[i:] {
+				cycle = append(cycle, string(q))
+			}
+			cycle = append(cycle, string(p))
+			return true
+		}
+		if done[p] {
+			return blocked[p]
+		}
+		stack = append(stack, p)
+		onStack[p] = true
+		found := false
+		for _, next := range loads[p] {
+			if visit(next) {
+				found = true
+				break
+			}
+		}
+		stack = stack[:len(stack)-1]
+		onStack[p] = false
+		done[p] = true
+		if found {
+			blocked[p] = true
+		}
+		return found
+	}
+	visit(start)
+	return cycle
+}
+
+// checker collects the problems for a single asset file
+type checker struct {
+	manager  *Manager
+	path     Path
+	problems []Problem
+
+	// loads are the assets that loading this one will load, parents and
+	// references
+	loads    []Path
+	loadable bool
+}
+
+func (c *checker) report(field string, format string, args ...any) {
+	c.problems = append(c.problems, Problem{
+		Path:    c.path,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkFile checks the file without loading it
+func (a *Manager) checkFile(path Path) *checker {
+	c := &checker{manager: a, path: path}
+	data, err := a.ReadFile(path)
+	if err != nil {
+		c.report("", "%v", err)
+		return c
+	}
+	container, err := decodeContainer(data)
+	if err != nil {
+		c.report("", "not a valid asset: %v", err)
+		return c
+	}
+	if container.Type == "" {
+		// json that is not an asset
+		return c
+	}
+	c.loadable = c.checkContainer("", container)
+	return c
+}
+
+// checkContainer checks the Type and the fields of container, it returns
+// false if the container is too broken to load.
+func (c *checker) checkContainer(field string, container *onDiskSaveFormat) bool {
+	desc := c.manager.descriptorForTypeName(container.Type)
+	if desc == nil {
+		c.report(field, "unknown Type %q, is it registered?", container.Type)
+		return false
+	}
+	if err := c.manager.migrateContainer(container); err != nil {
+		c.report(field, "%v", err)
+		return false
+	}
+	if container.Parent != "" {
+		container.Parent = c.manager.resolveReference(reference{Path: container.Parent, ID: container.ParentID})
+		c.loads = append(c.loads, container.Parent)
+		c.checkParent(field, container)
+	}
+	c.checkValue(field, desc.Type, nil, container.Inner)
+	return true
+}
+
+// checkParent checks that the direct parent of container exists and has
+// the same type
+func (c *checker) checkParent(field string, container *onDiskSaveFormat) {
+	data, err := c.manager.ReadFile(container.Parent)
+	if err != nil {
+		c.report(field, "missing parent %s", container.Parent)
+		return
+	}
+	parent, err := decodeContainer(data)
+	if err != nil {
+		c.report(field, "parent %s is not a valid asset: %v", container.Parent, err)
+		return
+	}
+	if parent.Type != container.Type {
+		c.report(field, "parent %s is a %s, not a %s", container.Parent, parent.Type, container.Type)
+	}
+}
+
+// checkValue checks v, which is in Common format and was saved from a
+// value of type t.  sf is the struct field that holds v, if any.
+func (c *checker) checkValue(field string, t reflect.Type, sf *reflect.StructField, v any) {
+	if v == nil {
+		return
+	}
+	if s := c.manager.serializerFor(t); s != nil {
+		if _, err := s.fromCommon(v); err != nil {
+			c.report(field, "%v", err)
+		}
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			c.report(field, "saved value is not a struct")
+			return
+		}
+		for key, fieldValue := range m {
+			structField, ok := t.FieldByName(key)
+			if !ok || !IsSavedField(&structField) {
+				c.report(joinField(field, key), "%s has no field %s", t.Name(), key)
+				continue
+			}
+			c.checkValue(joinField(field, key), structField.Type, &structField, fieldValue)
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			c.report(field, "saved value is not a map")
+			return
+		}
+		for _, key := range sortedMapKeys(m) {
+			if _, err := ParseMapKey(key, t.Key()); err != nil {
+				c.report(joinField(field, key), "%v", err)
+				continue
+			}
+			c.checkValue(joinField(field, key), t.Elem(), mapKeyField(sf, t, key), m[key])
+		}
+	case reflect.Pointer, reflect.Interface:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		if _, isInline := m["Inner"]; isInline {
+			c.checkContainer(field, containerFromCommonFormat(m))
+			return
+		}
+		if path, isRef := m["Path"].(string); isRef {
+			id, _ := m["ID"].(string)
+			c.checkReference(field, t, c.manager.resolveReference(reference{Path: Path(path), ID: id}))
+		}
+	case reflect.Slice, reflect.Array:
+		if diff, isDiff := asSliceDiff(v); isDiff {
+			// a child's changes to its parent's elements
+			ids := make([]string, 0, len(diff.Elements))
+			for id := range diff.Elements {
+				ids = append(ids, id)
+			}
+			sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+			for _, id := range ids {
+				c.checkValue(elementPath(field, id), t.Elem(), sf, diff.Elements[id])
+			}
+			return
+		}
+		list, ok := v.([]any)
+		if !ok {
+			// byte slices are saved as strings
+			return
+		}
+		for i, item := range list {
+			c.checkValue(fmt.Sprintf("%s[%d]", field, i), t.Elem(), sf, item)
+		}
+	case reflect.String:
+		if t == pathType {
+			if path, ok := v.(string); ok && path != "" {
+				c.checkPath(field, sf, Path(path))
+			}
+		}
+	}
+}
+
+func (c *checker) checkReference(field string, t reflect.Type, path Path) {
+	c.loads = append(c.loads, path)
+	data, err := c.manager.ReadFile(path)
+	if err != nil {
+		c.report(field, "missing asset %s", path)
+		return
+	}
+	container, err := decodeContainer(data)
+	if err != nil {
+		c.report(field, "%s is not a valid asset: %v", path, err)
+		return
+	}
+	desc := c.manager.descriptorForTypeName(container.Type)
+	if desc == nil {
+		// reported against the referenced file
+		return
+	}
+	if !reflect.PointerTo(desc.Type).AssignableTo(t) {
+		c.report(field, "%s is a %s, which cannot be assigned to %s", path, desc.Name, t)
+	}
+}
+
+func (c *checker) checkPath(field string, sf *reflect.StructField, path Path) {
+	if !c.manager.fileExists(path) {
+		c.report(field, "missing file %s", path)
+		return
+	}
+	if sf == nil {
+		return
+	}
+	filters := PathFilters(sf)
+	if len(filters) == 0 {
+		return
+	}
+	lower := strings.ToLower(string(path))
+	for _, filter := range filters {
+		if strings.Contains(lower, filter) {
+			return
+		}
+	}
+	c.report(field, "%s does not match the filter %s", path, strings.Join(filters, ","))
+}
+
+// checkLoad loads the asset, and then looks for pointers that Save would
+// drop.
+func (c *checker) checkLoad() {
+	loaded, err := c.loadRecovered()
+	// the checks above have better messages for most problems, so only
+	// report load errors for fields without a problem already
+	reported := map[string]bool{}
+	for _, p := range c.problems {
+		reported[p.Field] = true
+	}
+	for _, loadErr := range LoadErrors(err) {
+		if loadErr.Path != c.path && loadErr.Path != "" {
+			// reported against the other asset
+			continue
+		}
+		if !reported[loadErr.Field] {
+			c.report(loadErr.Field, "failed to load: %v", loadErr.Err)
+		}
+	}
+	if loaded != nil {
+		c.checkUnsaveable("", reflect.ValueOf(loaded).Elem(), false)
+	}
+}
+
+func (c *checker) loadRecovered() (loaded Asset, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.manager.Load(c.path)
+}
+
+// checkUnsaveable mirrors toCommonFormat, reporting the pointers that it
+// would log as "will not be saved".  inline is true when v is held by a
+// field tagged inline.
+func (c *checker) checkUnsaveable(field string, v reflect.Value, inline bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Interface && v.Elem().Kind() != reflect.Pointer {
+			// an interface holding a value is saved as that value
+			c.checkUnsaveable(field, v.Elem(), false)
+			return
+		}
+		if inline {
+			c.checkUnsaveable(field, reflect.Indirect(v.Elem()), false)
+			return
+		}
+		if _, err := c.manager.GetLoadPathForAsset(v.Interface()); err != nil {
+			c.report(field, "will not be saved, it is not inline and not a loaded asset")
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !IsSavedField(&sf) {
+				continue
+			}
+			_, inline := GetFlatTag(&sf, "inline")
+			c.checkUnsaveable(joinField(field, sf.Name), FieldValue(v, i), inline)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.checkUnsaveable(fmt.Sprintf("%s[%d]", field, i), v.Index(i), inline)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := MapKeyString(iter.Key())
+			if err != nil {
+				c.report(field, "will not be saved, %v", err)
+				return
+			}
+			c.checkUnsaveable(joinField(field, key), iter.Value(), inline)
+		}
+	}
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	if strings.HasPrefix(name, "[") {
+		// a slice index
+		return parent + name
+	}
+	return parent + "." + name
+}
+
+func (a *Manager) fileExists(path Path) bool {
+	for _, fsys := range a.fileSystems() {
+		if _, err := fs.Stat(fsys.FileSystem, string(path)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PathFilters returns the lower case filters of an asset.Path field, from
+// either a `flat:"filter:png,jpg"` or a `filter:"png,jpg"` tag.  A Path
+// with filters should name a file that contains one of them.
+func PathFilters(sf *reflect.StructField) []string {
+	val, ok := GetFlatTag(sf, "filter")
+	if !ok {
+		val, ok = sf.Tag.Lookup("filter")
+	}
+	if !ok || val == "" {
+		return nil
+	}
+	return strings.Split(strings.ToLower(val), ",")
+}
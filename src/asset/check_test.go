@@ -0,0 +1,93 @@
+package asset_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type checkShip struct {
+	Name   string
+	Image  asset.Path `flat:"filter:png"`
+	Target *checkShip
+	Parts  []any `flat:"inline"`
+}
+
+func (s *checkShip) PostLoad() {
+	if s.Name == "runtime" {
+		s.Target = &checkShip{}
+	}
+}
+
+type checkOther struct{}
+
+func checkAsset(typ string, parent string, inner string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"Type": "github.com/bradbev/flatland/src/asset_test.%s",
+		"Parent": "%s",
+		"Inner": %s
+	}`, typ, parent, inner))
+}
+
+func ref(path string) string {
+	return fmt.Sprintf(`{"Type": "github.com/bradbev/flatland/src/asset_test.checkShip", "Path": "%s"}`, path)
+}
+
+func TestCheck(t *testing.T) {
+	wfs := newWriteFS()
+	files := map[string][]byte{
+		"img/ship.png":     []byte("png"),
+		"img/ship.txt":     []byte("txt"),
+		"good.json":        checkAsset("checkShip", "", `{"Image": "img/ship.png"}`),
+		"child.json":       checkAsset("checkShip", "good.json", `{"Name": "child"}`),
+		"other.json":       checkAsset("checkOther", "", `{}`),
+		"unknown.json":     checkAsset("notRegistered", "", `{}`),
+		"broken.json":      []byte("{ nope"),
+		"refs.json":        checkAsset("checkShip", "", `{"Target": `+ref("missing.json")+`, "Image": "img/ship.txt", "Speed": 3}`),
+		"paths.json":       checkAsset("checkShip", "", `{"Image": "img/gone.png"}`),
+		"wrongref.json":    checkAsset("checkShip", "", `{"Target": `+ref("other.json")+`}`),
+		"orphan.json":      checkAsset("checkShip", "nothere.json", `{}`),
+		"wrongparent.json": checkAsset("checkShip", "other.json", `{}`),
+		"cycleA.json":      checkAsset("checkShip", "cycleB.json", `{}`),
+		"cycleB.json":      checkAsset("checkShip", "cycleA.json", `{}`),
+		"loop1.json":       checkAsset("checkShip", "", `{"Target": `+ref("loop2.json")+`}`),
+		"loop2.json":       checkAsset("checkShip", "", `{"Target": `+ref("loop1.json")+`}`),
+		"usesloop.json":    checkAsset("checkShip", "", `{"Target": `+ref("loop1.json")+`}`),
+		"inline.json": checkAsset("checkShip", "", `{"Parts": [
+			{"Type": "github.com/bradbev/flatland/src/asset_test.checkShip", "Inner": {"Image": "img/ship.txt"}},
+			{"Type": "nope", "Inner": {}}
+		]}`),
+		"runtime.json": checkAsset("checkShip", "", `{"Name": "runtime"}`),
+	}
+	for path, data := range files {
+		wfs.WriteFile(asset.Path(path), data)
+	}
+
+	m := asset.NewManager()
+	m.RegisterAsset(checkShip{})
+	m.RegisterAsset(checkOther{})
+	m.RegisterFileSystem(wfs.fs, 0)
+
+	var problems []string
+	for _, p := range m.Check() {
+		problems = append(problems, p.String())
+	}
+	assert.Equal(t, []string{
+		"broken.json: not a valid asset: invalid character 'n' looking for beginning of object key string",
+		"cycleA.json: cyclic references cycleA.json -> cycleB.json -> cycleA.json",
+		"inline.json: Parts[0].Image: img/ship.txt does not match the filter png",
+		"inline.json: Parts[1]: unknown Type \"nope\", is it registered?",
+		"loop1.json: cyclic references loop1.json -> loop2.json -> loop1.json",
+		"orphan.json: missing parent nothere.json",
+		"paths.json: Image: missing file img/gone.png",
+		"refs.json: Image: img/ship.txt does not match the filter png",
+		"refs.json: Speed: checkShip has no field Speed",
+		"refs.json: Target: missing asset missing.json",
+		"runtime.json: Target: will not be saved, it is not inline and not a loaded asset",
+		"unknown.json: unknown Type \"github.com/bradbev/flatland/src/asset_test.notRegistered\", is it registered?",
+		"wrongparent.json: parent other.json is a github.com/bradbev/flatland/src/asset_test.checkOther, not a github.com/bradbev/flatland/src/asset_test.checkShip",
+		"wrongref.json: Target: other.json is a checkOther, which cannot be assigned to *asset_test.checkShip",
+	}, problems)
+}
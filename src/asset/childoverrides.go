@@ -20,12 +20,20 @@ func (c *childOverrides) PathHasOverride(path string) bool {
 	return exists
 }
 
-func (c *childOverrides) BuildFromCommonFormat(commonFormat any) {
-	c.buildFromCommonFormat(commonFormat, []string{})
+// BuildFromCommonFormat records an override for every value in
+// commonFormat, which holds the values of a child of type t that differ
+// from its parent
+func (c *childOverrides) BuildFromCommonFormat(commonFormat any, t reflect.Type) {
+	c.buildFromCommonFormat(commonFormat, t, "")
 }
 
+// RemovePath removes the override for path and for everything inside of it
 func (c *childOverrides) RemovePath(path string) {
-	delete(c.overrides, path)
+	for p := range c.overrides {
+		if p == path || strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[") {
+			delete(c.overrides, p)
+		}
+	}
 }
 
 func (c *childOverrides) Empty() bool {
@@ -36,20 +44,77 @@ func (c *childOverrides) AddPath(path string) {
 	c.overrides[path] = struct{}{}
 }
 
-func (c *childOverrides) buildFromCommonFormat(commonFormat any, nameStack []string) {
-	v := reflect.ValueOf(commonFormat)
+// coversPath reports whether path, or a value that holds it, is overridden
+func (c *childOverrides) coversPath(path string) bool {
+	for {
+		if c.PathHasOverride(path) {
+			return true
+		}
+		end := strings.LastIndexAny(path, ".[")
+		if end <= 0 {
+			return false
+		}
+		path = path[:end]
+	}
+}
+
+// coversPathOrInside reports whether path, a value that holds it, or a
+// value inside of it is overridden
+func (c *childOverrides) coversPathOrInside(path string) bool {
+	if c.coversPath(path) {
+		return true
+	}
+	for p := range c.overrides {
+		if strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[") {
+			return true
+		}
+	}
+	return false
+}
 
-	if v.Type().Kind() != reflect.Map {
-		return
+// overriddenElements returns the ids of the elements of the slice at path
+// that have overrides
+func (c *childOverrides) overriddenElements(path string) map[string]bool {
+	ids := map[string]bool{}
+	for p := range c.overrides {
+		if rest, ok := strings.CutPrefix(p, path+"["); ok {
+			if end := strings.Index(rest, "]"); end > 0 {
+				ids[rest[:end]] = true
+			}
+		}
 	}
+	return ids
+}
 
-	iter := v.MapRange()
-	for iter.Next() {
-		k, v := iter.Key(), iter.Value()
-		if v.Elem().Kind() == reflect.Map {
-			c.buildFromCommonFormat(v.Elem().Interface(), append(nameStack, k.String()))
-		} else {
-			c.AddPath(strings.Join(append(nameStack, k.String()), "."))
+// buildFromCommonFormat walks commonFormat, which was saved from a value of
+// type t.  t is nil when the type is not known.
+func (c *childOverrides) buildFromCommonFormat(commonFormat any, t reflect.Type, path string) {
+	m, isMap := commonFormat.(map[string]any)
+	switch {
+	case isMap && t != nil && t.Kind() == reflect.Slice:
+		diff, _ := asSliceDiff(m)
+		for id, element := range diff.Elements {
+			c.buildFromCommonFormat(element, t.Elem(), elementPath(path, id))
+		}
+		for _, id := range diff.Removed {
+			c.AddPath(elementPath(path, id))
+		}
+		if diff.Order != nil {
+			c.AddPath(orderPath(path))
+		}
+	case isMap && (t == nil || t.Kind() == reflect.Struct || t.Kind() == reflect.Map):
+		for k, v := range m {
+			var valueType reflect.Type
+			if t != nil && t.Kind() == reflect.Struct {
+				if sf, ok := t.FieldByName(k); ok {
+					valueType = sf.Type
+				}
+			} else if t != nil {
+				valueType = t.Elem()
+			}
+			c.buildFromCommonFormat(v, valueType, joinField(path, k))
 		}
+	default:
+		c.AddPath(path)
 	}
 }
@@ -0,0 +1,36 @@
+package asset
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// Cook writes every file that the Manager can read to dest.  Asset files are
+// converted to the binary format, all other files (images, fonts, etc) are
+// copied unchanged.  Cooked assets keep their .json file names, so paths
+// stored in other assets remain valid.
+// When the same path exists in several file systems the file that
+// ReadFile would return is the one that is cooked.
+func (a *Manager) Cook(dest WriteableFileSystem) error {
+	return a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := a.ReadFile(Path(path))
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(path, ".json") && !isBinaryFormat(data) {
+			if container, err := decodeContainer(data); err == nil && container.Type != "" {
+				data, err = a.encodeContainer(a.withRawBytes(container), SaveFormatBinary)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return dest.WriteFile(Path(path), data)
+	})
+}
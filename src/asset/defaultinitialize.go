@@ -34,8 +34,8 @@ func callAllDefaultInitializers(obj any) {
 	case reflect.Struct:
 		for i := 0; i < t.NumField(); i++ {
 			tField := t.Field(i)
-			if tField.IsExported() {
-				vField := v.Field(i)
+			if IsSavedField(&tField) {
+				vField := FieldValue(v, i)
 				processValue(vField)
 			}
 		}
@@ -0,0 +1,278 @@
+package asset
+
+import (
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// dependencyIndex records which files each asset file refers to, and the
+// reverse.  References are pointers to other assets, Parent links and
+// asset.Path fields.
+type dependencyIndex struct {
+	dependencies map[Path]map[Path]struct{}
+	referencers  map[Path]map[Path]struct{}
+
+	// ids maps an asset ID to the file that holds it
+	ids map[string]Path
+	// duplicateIDs are the files whose ID is already used by the file in
+	// ids, by ID
+	duplicateIDs map[string][]Path
+}
+
+func newDependencyIndex() *dependencyIndex {
+	return &dependencyIndex{
+		dependencies: map[Path]map[Path]struct{}{},
+		referencers:  map[Path]map[Path]struct{}{},
+		ids:          map[string]Path{},
+		duplicateIDs: map[string][]Path{},
+	}
+}
+
+func (d *dependencyIndex) add(from, to Path) {
+	if to == "" || from == to {
+		return
+	}
+	if d.dependencies[from] == nil {
+		d.dependencies[from] = map[Path]struct{}{}
+	}
+	d.dependencies[from][to] = struct{}{}
+	if d.referencers[to] == nil {
+		d.referencers[to] = map[Path]struct{}{}
+	}
+	d.referencers[to][from] = struct{}{}
+}
+
+func sortedPaths(set map[Path]struct{}) []Path {
+	result := make([]Path, 0, len(set))
+	for p := range set {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Dependencies returns the files that the asset at path refers to.  The
+// dependency index is built on first use by reading every asset file, and
+// is rebuilt after assets are saved.
+func (a *Manager) Dependencies(path Path) []Path {
+	return sortedPaths(a.dependencyIndex().dependencies[path])
+}
+
+// Referencers returns the asset files that refer to path, path may be an
+// asset or any other file (such as an image).
+func (a *Manager) Referencers(path Path) []Path {
+	return sortedPaths(a.dependencyIndex().referencers[path])
+}
+
+// InvalidateDependencies causes the dependency index to be rebuilt on next
+// use.  Call it when files are changed without going through Save.
+func (a *Manager) InvalidateDependencies() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dependencies = nil
+}
+
+func (a *Manager) dependencyIndex() *dependencyIndex {
+	a.mu.RLock()
+	index := a.dependencies
+	a.mu.RUnlock()
+	if index != nil {
+		return index
+	}
+
+	index = a.buildDependencyIndex()
+	a.mu.Lock()
+	a.dependencies = index
+	a.mu.Unlock()
+	return index
+}
+
+func (a *Manager) buildDependencyIndex() *dependencyIndex {
+	index := newDependencyIndex()
+	// the IDs must all be known before references can be resolved
+	containers := map[Path]*onDiskSaveFormat{}
+	a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := a.ReadFile(Path(path))
+		if err != nil {
+			return nil
+		}
+		container, err := decodeContainer(data)
+		if err != nil || container.Type == "" {
+			return nil
+		}
+		containers[Path(path)] = container
+		if container.ID == "" {
+			return nil
+		}
+		if _, used := index.ids[container.ID]; used {
+			index.duplicateIDs[container.ID] = append(index.duplicateIDs[container.ID], Path(path))
+		} else {
+			index.ids[container.ID] = Path(path)
+		}
+		return nil
+	})
+	for path, container := range containers {
+		a.collectContainerDependencies(container, index.ids, func(to Path) {
+			index.add(path, to)
+		})
+	}
+	return index
+}
+
+// collectContainerDependencies calls add with the path of every reference
+// in container.  References with an ID are resolved with ids.
+func (a *Manager) collectContainerDependencies(container *onDiskSaveFormat, ids map[string]Path, add func(Path)) {
+	a.visitContainerReferences(container, func(ref reference) reference {
+		if path, found := ids[ref.ID]; found && ref.ID != "" {
+			add(path)
+		} else {
+			add(ref.Path)
+		}
+		return ref
+	})
+}
+
+// reference is a link from an asset file to another file.  Parents and
+// references to other assets may have the ID of the asset they refer to,
+// then Path is only a hint of where to find it.
+type reference struct {
+	Path Path
+	ID   string
+}
+
+// visitContainerReferences calls visit for every reference in container,
+// including its Parent.  The reference is replaced by the one that visit
+// returns.  It returns true if any reference was changed.
+func (a *Manager) visitContainerReferences(container *onDiskSaveFormat, visit func(reference) reference) bool {
+	changed := false
+	if container.Parent != "" {
+		parent := reference{Path: container.Parent, ID: container.ParentID}
+		if newParent := visit(parent); newParent != parent {
+			container.Parent = newParent.Path
+			container.ParentID = newParent.ID
+			changed = true
+		}
+	}
+	var t reflect.Type
+	if desc := a.descriptorForTypeName(container.Type); desc != nil {
+		t = desc.Type
+	}
+	inner, innerChanged := a.visitReferences(t, container.Inner, visit)
+	container.Inner = inner
+	return changed || innerChanged
+}
+
+// visitReferences walks v, which is in Common format, and calls visit for
+// every reference it finds.  t is the type that v was saved from, when t is
+// nil (the asset type is not registered) only references to other assets
+// are found, asset.Path fields need the type to be recognised.
+// The returned value has the references replaced by the result of visit.
+// asset.Path fields cannot hold an ID, so the ID visit returns for them is
+// ignored.
+func (a *Manager) visitReferences(t reflect.Type, v any, visit func(reference) reference) (any, bool) {
+	changed := false
+	switch value := v.(type) {
+	case map[string]any:
+		if t != nil && t.Kind() == reflect.Struct {
+			for k, fieldValue := range value {
+				if sf, ok := t.FieldByName(k); ok {
+					newValue, c := a.visitReferences(sf.Type, fieldValue, visit)
+					if c {
+						value[k] = newValue
+						changed = true
+					}
+				}
+			}
+			return value, changed
+		}
+		if t != nil && t.Kind() == reflect.Slice {
+			// a child's changes to its parent's elements
+			if diff, _ := asSliceDiff(value); diff.Elements != nil {
+				if _, c := a.visitReferences(reflect.MapOf(reflect.TypeOf(""), t.Elem()), diff.Elements, visit); c {
+					changed = true
+				}
+			}
+			return value, changed
+		}
+		if t != nil && t.Kind() == reflect.Map {
+			for k, itemValue := range value {
+				newValue, c := a.visitReferences(t.Elem(), itemValue, visit)
+				if c {
+					value[k] = newValue
+					changed = true
+				}
+			}
+			return value, changed
+		}
+		if t == nil || t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+			if _, isContainer := value["Inner"]; isContainer {
+				inline := containerFromCommonFormat(value)
+				if a.visitContainerReferences(inline, visit) {
+					value["Parent"] = string(inline.Parent)
+					setOrDelete(value, "ParentID", inline.ParentID)
+					value["Inner"] = inline.Inner
+					changed = true
+				}
+				return value, changed
+			}
+			if path, isRef := value["Path"].(string); isRef {
+				if _, hasType := value["Type"]; hasType {
+					id, _ := value["ID"].(string)
+					ref := reference{Path: Path(path), ID: id}
+					if newRef := visit(ref); newRef != ref {
+						value["Path"] = string(newRef.Path)
+						setOrDelete(value, "ID", newRef.ID)
+						changed = true
+					}
+					return value, changed
+				}
+			}
+		}
+		if t == nil {
+			for k, fieldValue := range value {
+				newValue, c := a.visitReferences(nil, fieldValue, visit)
+				if c {
+					value[k] = newValue
+					changed = true
+				}
+			}
+		}
+		return value, changed
+	case []any:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i, item := range value {
+			newValue, c := a.visitReferences(elem, item, visit)
+			if c {
+				value[i] = newValue
+				changed = true
+			}
+		}
+		return value, changed
+	case string:
+		if t == pathType && value != "" {
+			if newRef := visit(reference{Path: Path(value)}); newRef.Path != Path(value) {
+				return string(newRef.Path), true
+			}
+		}
+	}
+	return v, false
+}
+
+// setOrDelete sets m[key] to value, or removes key when value is empty
+func setOrDelete(m map[string]any, key, value string) {
+	if value == "" {
+		delete(m, key)
+	} else {
+		m[key] = value
+	}
+}
+
+var pathType = reflect.TypeOf(Path(""))
@@ -0,0 +1,57 @@
+package asset_test
+
+import (
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type depLeaf struct {
+	Image asset.Path
+}
+
+type depRoot struct {
+	Ref    *depLeaf
+	Refs   []any
+	Inline *depLeaf `flat:"inline"`
+	Images []asset.Path
+}
+
+func TestDependencies(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+
+	leaf := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	other := &depLeaf{Image: "raw/other.png"}
+	assert.NoError(t, m.Save("other.json", other))
+
+	child := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.SetParent(child, leaf))
+	assert.NoError(t, m.Save("child.json", child))
+
+	inline := &depLeaf{Image: "raw/inline.png"}
+	assert.NoError(t, m.SetParent(inline, other))
+	root := &depRoot{
+		Ref:    leaf,
+		Refs:   []any{other},
+		Inline: inline,
+		Images: []asset.Path{"raw/a.png", ""},
+	}
+	assert.NoError(t, m.Save("root.json", root))
+
+	assert.Equal(t,
+		[]asset.Path{"leaf.json", "other.json", "raw/a.png", "raw/inline.png"},
+		m.Dependencies("root.json"))
+	// the child inherits Image, so only depends on the image via its parent
+	assert.Equal(t, []asset.Path{"leaf.json"}, m.Dependencies("child.json"))
+	assert.Equal(t, []asset.Path{"child.json", "root.json"}, m.Referencers("leaf.json"))
+	assert.Equal(t, []asset.Path{"leaf.json"}, m.Referencers("raw/leaf.png"))
+	assert.Empty(t, m.Referencers("root.json"))
+
+	// saving changes the index
+	root.Ref = nil
+	assert.NoError(t, m.Save("root.json", root))
+	assert.Equal(t, []asset.Path{"child.json"}, m.Referencers("leaf.json"))
+}
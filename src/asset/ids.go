@@ -0,0 +1,173 @@
+package asset
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Every saved asset has a stable ID that is kept when the file is moved or
+// renamed.  References to other assets, and Parent links, are saved with
+// both the ID and the Path of the asset they refer to.  The Path is a hint,
+// it is used when the file there still has the ID, otherwise the ID is
+// looked up in the dependency index, which reads every registered file
+// system.  References saved before assets had IDs only have a Path, and
+// load as they always did.
+
+// newAssetID returns a new random ID
+func newAssetID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// AssetID returns the ID of a loaded or saved asset, or "" when it does not
+// have one, for example because it was saved before assets had IDs
+func (a *Manager) AssetID(asset Asset) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.AssetIDs[asset]
+}
+
+// idForSave returns the ID that toSave is saved at path with.  An asset
+// keeps its ID, unless it is being saved to a new path as a copy.  A new
+// asset takes over the ID of the file it replaces, so that references to
+// that file still find it.
+func (a *Manager) idForSave(toSave Asset, path Path) string {
+	a.mu.RLock()
+	id := a.AssetIDs[toSave]
+	loadPath, hasPath := a.AssetToLoadPath[toSave]
+	a.mu.RUnlock()
+	if id != "" && (!hasPath || loadPath == path) {
+		return id
+	}
+	if existing := a.idAtPath(path); existing != "" {
+		return existing
+	}
+	return newAssetID()
+}
+
+// idAtPath returns the ID of the asset file at path, or "" if there is no
+// such file or it has no ID
+func (a *Manager) idAtPath(path Path) string {
+	a.mu.RLock()
+	loaded, isLoaded := a.LoadPathToAsset[path]
+	id := a.AssetIDs[loaded]
+	a.mu.RUnlock()
+	if isLoaded && id != "" {
+		return id
+	}
+	data, err := a.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	container, err := decodeContainer(data)
+	if err != nil {
+		return ""
+	}
+	return container.ID
+}
+
+// resolveReference returns the path of the asset that ref refers to.  The
+// index is only read when the file at ref.Path does not have ref.ID.
+func (a *Manager) resolveReference(ref reference) Path {
+	if ref.ID == "" || a.idAtPath(ref.Path) == ref.ID {
+		return ref.Path
+	}
+	if path, found := a.dependencyIndex().ids[ref.ID]; found {
+		return path
+	}
+	return ref.Path
+}
+
+// StampIDs gives every asset that the Manager can read, and does not have
+// an ID, a new one.  References and Parent links to assets that have an
+// ID are given the ID too.  Assets are written back with the writable file
+// system, keeping the format (json or binary) they were saved in, and the
+// paths of the files that changed are returned.  It is run once, to move
+// content saved before assets had IDs, see flatcmd.Upgrade.
+func (a *Manager) StampIDs() ([]Path, error) {
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	a.mu.RUnlock()
+	if writeFS == nil {
+		return nil, fmt.Errorf("StampIDs needs a writable file system")
+	}
+
+	type assetFile struct {
+		container *onDiskSaveFormat
+		format    SaveFormat
+		changed   bool
+	}
+	files := map[Path]*assetFile{}
+	ids := map[Path]string{}
+	err := a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := a.ReadFile(Path(path))
+		if err != nil {
+			return err
+		}
+		container, err := decodeContainer(data)
+		if err != nil || container.Type == "" {
+			// not an asset
+			return nil
+		}
+		file := &assetFile{container: container}
+		if isBinaryFormat(data) {
+			file.format = SaveFormatBinary
+		}
+		if container.ID == "" {
+			container.ID = newAssetID()
+			file.changed = true
+		}
+		files[Path(path)] = file
+		ids[Path(path)] = container.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stamped []Path
+	for path, file := range files {
+		referencesChanged := a.visitContainerReferences(file.container, func(ref reference) reference {
+			if ref.ID == "" {
+				ref.ID = ids[ref.Path]
+			}
+			return ref
+		})
+		if !file.changed && !referencesChanged {
+			continue
+		}
+		data, err := a.encodeContainer(file.container, file.format)
+		if err != nil {
+			return stamped, err
+		}
+		if err := writeFS.WriteFile(path, data); err != nil {
+			return stamped, err
+		}
+		a.wrote(path)
+		stamped = append(stamped, path)
+	}
+	sort.Slice(stamped, func(i, j int) bool { return stamped[i] < stamped[j] })
+
+	a.mu.Lock()
+	for path, loaded := range a.LoadPathToAsset {
+		if id := ids[path]; id != "" {
+			a.AssetIDs[loaded] = id
+		}
+	}
+	a.dependencies = nil
+	a.mu.Unlock()
+	return stamped, nil
+}
@@ -0,0 +1,106 @@
+package asset_test
+
+import (
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDsSurviveMoves(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+	wfs.WriteFile("raw/parent.png", []byte("png"))
+	wfs.WriteFile("raw/leaf.png", []byte("png"))
+	parent := &depLeaf{Image: "raw/parent.png"}
+	assert.NoError(t, m.Save("parent.json", parent))
+	leaf := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.SetParent(leaf, parent))
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	assert.NoError(t, m.Save("root.json", &depRoot{Ref: leaf, Inline: &depLeaf{}}))
+
+	leafID := m.AssetID(leaf)
+	assert.NotEmpty(t, leafID)
+	assert.NotEqual(t, leafID, m.AssetID(parent))
+	ref := readContainer(t, wfs, "root.json")["Inner"].(js)["Ref"].(js)
+	assert.Equal(t, leafID, ref["ID"])
+	assert.Equal(t, "leaf.json", ref["Path"])
+	assert.Equal(t, m.AssetID(parent), readContainer(t, wfs, "leaf.json")["ParentID"])
+
+	// move the files without telling the Manager, as a file manager would
+	assert.NoError(t, wfs.Rename("leaf.json", "moved/leaf.json"))
+	assert.NoError(t, wfs.Rename("parent.json", "moved/parent.json"))
+
+	fresh := newTestManager(wfs, depLeaf{}, depRoot{})
+	loaded, err := fresh.Load("root.json")
+	assert.NoError(t, err)
+	loadedLeaf := loaded.(*depRoot).Ref
+	assert.Equal(t, asset.Path("raw/leaf.png"), loadedLeaf.Image)
+	assert.Equal(t, leafID, fresh.AssetID(loadedLeaf))
+	assert.Equal(t, asset.Path("moved/parent.json"), fresh.GetParent(loadedLeaf))
+	assert.Equal(t, []asset.Path{"moved/leaf.json"}, fresh.Dependencies("root.json"))
+	assert.Equal(t, []asset.Path{"moved/leaf.json"}, fresh.Referencers("moved/parent.json"))
+	assert.Empty(t, fresh.Check())
+
+	// saving again keeps the ID and fixes the hint
+	assert.NoError(t, fresh.Save("root.json", loaded))
+	ref = readContainer(t, wfs, "root.json")["Inner"].(js)["Ref"].(js)
+	assert.Equal(t, js{"Type": ref["Type"], "Path": "moved/leaf.json", "ID": leafID}, ref)
+	path, _ := fresh.GetLoadPathForAsset(loadedLeaf)
+	assert.Equal(t, asset.Path("moved/leaf.json"), path)
+
+	// saving a copy gives it a new ID
+	assert.NoError(t, fresh.Save("copy.json", loadedLeaf))
+	assert.NotEqual(t, leafID, fresh.AssetID(loadedLeaf))
+	assert.Equal(t, leafID, readContainer(t, wfs, "moved/leaf.json")["ID"])
+}
+
+func TestStampIDs(t *testing.T) {
+	wfs := newWriteFS()
+	// content saved before assets had IDs
+	wfs.WriteFile("leaf.json", []byte(`{
+		"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf",
+		"Parent": "",
+		"Inner": {"Image": "raw/leaf.png"}
+	}`))
+	wfs.WriteFile("root.json", []byte(`{
+		"Type": "github.com/bradbev/flatland/src/asset_test.depRoot",
+		"Parent": "",
+		"Inner": {
+			"Ref": {"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf", "Path": "leaf.json"},
+			"Inline": {"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf", "Parent": "leaf.json", "Inner": null}
+		}
+	}`))
+	wfs.WriteFile("raw/leaf.png", []byte("png"))
+
+	// path only references still load
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+	loaded, err := m.Load("root.json")
+	assert.NoError(t, err)
+	assert.Equal(t, asset.Path("raw/leaf.png"), loaded.(*depRoot).Ref.Image)
+	assert.Empty(t, m.AssetID(loaded))
+
+	stamped, err := m.StampIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"leaf.json", "root.json"}, stamped)
+	leafID := readContainer(t, wfs, "leaf.json")["ID"]
+	assert.NotEmpty(t, leafID)
+	assert.Equal(t, leafID, m.AssetID(loaded.(*depRoot).Ref))
+	root := readContainer(t, wfs, "root.json")
+	assert.NotEmpty(t, root["ID"])
+	inner := root["Inner"].(js)
+	assert.Equal(t, leafID, inner["Ref"].(js)["ID"])
+	assert.Equal(t, leafID, inner["Inline"].(js)["ParentID"])
+	assert.Nil(t, inner["Inline"].(js)["ID"], "inline assets do not have IDs")
+
+	stamped, err = m.StampIDs()
+	assert.NoError(t, err)
+	assert.Empty(t, stamped, "stamping is only needed once")
+
+	// copying a file by hand duplicates its ID
+	data, _ := m.ReadFile("leaf.json")
+	wfs.WriteFile("other.json", data)
+	problems := newTestManager(wfs, depLeaf{}, depRoot{}).Check()
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "other.json: has the same ID as leaf.json", problems[0].String())
+}
@@ -0,0 +1,77 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LoadError is a problem found while loading an asset.  Loading carries on
+// past problems with individual fields, so Load may return several
+// LoadErrors joined together (see errors.Join), use LoadErrors to get them
+// back.
+type LoadError struct {
+	// Path is the asset file being loaded
+	Path Path
+	// Field is the dotted path to the field that failed, for example
+	// "Ship.Image".  It is empty when the whole asset failed to load.
+	Field string
+	Err   error
+}
+
+func (e *LoadError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Path, e.Field, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// LoadErrors flattens err, as returned by Load, into its LoadErrors.
+// Errors that are not LoadErrors are returned as a LoadError with only Err
+// set.
+func LoadErrors(err error) []*LoadError {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var result []*LoadError
+		for _, e := range joined.Unwrap() {
+			result = append(result, LoadErrors(e)...)
+		}
+		return result
+	}
+	var loadErr *LoadError
+	if errors.As(err, &loadErr) && loadErr == err {
+		return []*LoadError{loadErr}
+	}
+	return []*LoadError{{Err: err}}
+}
+
+// inField places the errors in err, that do not yet have a Path, inside of
+// field.  Errors that already have a Path come from loading a different
+// asset and are left alone.
+func inField(err error, field string) error {
+	var result []error
+	for _, loadErr := range LoadErrors(err) {
+		if loadErr.Path == "" {
+			loadErr.Field = joinField(field, loadErr.Field)
+		}
+		result = append(result, loadErr)
+	}
+	return errors.Join(result...)
+}
+
+// inAsset sets the Path of the errors in err that do not have one
+func inAsset(err error, path Path) error {
+	var result []error
+	for _, loadErr := range LoadErrors(err) {
+		if loadErr.Path == "" {
+			loadErr.Path = path
+		}
+		result = append(result, loadErr)
+	}
+	return errors.Join(result...)
+}
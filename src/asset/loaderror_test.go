@@ -0,0 +1,72 @@
+package asset_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type loadErrorAsset struct {
+	Ref   *depLeaf
+	Parts []any `flat:"inline"`
+	Count int
+	Name  string
+}
+
+const loadErrorJSON = `{
+	"Type": "github.com/bradbev/flatland/src/asset_test.loadErrorAsset",
+	"Inner": {
+		"Ref": {"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf", "Path": "missing.json"},
+		"Parts": [
+			{"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf", "Inner": {"Image": "ok.png"}},
+			{"Type": "unknown", "Inner": {}},
+			{"Type": "github.com/bradbev/flatland/src/asset_test.depLeaf", "Inner": {"Image": 3}}
+		],
+		"Count": "three",
+		"Name": "loaded anyway"
+	}
+}`
+
+func TestLoadErrors(t *testing.T) {
+	wfs := newWriteFS()
+	wfs.WriteFile("broken.json", []byte(loadErrorJSON))
+	m := asset.NewManager()
+	m.RegisterAsset(loadErrorAsset{})
+	m.RegisterAsset(depLeaf{})
+	m.RegisterFileSystem(wfs.fs, 0)
+
+	loaded, err := m.Load("broken.json")
+	assert.Error(t, err)
+	// every problem is reported, and the rest of the asset still loads
+	broken := loaded.(*loadErrorAsset)
+	assert.Equal(t, "loaded anyway", broken.Name)
+	assert.Equal(t, &depLeaf{Image: "ok.png"}, broken.Parts[0])
+
+	var fields []string
+	for _, loadErr := range asset.LoadErrors(err) {
+		assert.Equal(t, asset.Path("broken.json"), loadErr.Path)
+		fields = append(fields, loadErr.Field)
+	}
+	assert.Equal(t, []string{"Ref", "Parts[1]", "Parts[2].Image", "Count"}, fields)
+
+	var loadErr *asset.LoadError
+	assert.True(t, errors.As(err, &loadErr))
+
+	// later loads return the same asset and the same problems
+	again, againErr := m.Load("broken.json")
+	assert.True(t, again == loaded)
+	assert.Equal(t, err, againErr)
+
+	// the problems go once the asset is saved without them
+	m.RegisterWritableFileSystem(wfs)
+	assert.NoError(t, m.Save("broken.json", loaded))
+	_, err = m.Load("broken.json")
+	assert.NoError(t, err)
+
+	// a file that can't be read is a single error without a field
+	loaded, err = m.Load("missing.json")
+	assert.Nil(t, loaded)
+	assert.Equal(t, []*asset.LoadError{{Path: "missing.json", Err: asset.LoadErrors(err)[0].Err}}, asset.LoadErrors(err))
+}
@@ -0,0 +1,93 @@
+package asset
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Maps are saved in Common format as map[string]any, the same as structs.
+// Keys may be strings or integers, integer keys are saved as their decimal
+// string.  Each key is treated like a struct field named by the key, so a
+// child asset overrides single keys and the override path of a key is
+// "Field.key".  A child that removes one of its parent's keys saves the
+// key with a nil value, values that are nil are otherwise never saved.
+
+// MapKeyString returns the string that key is saved as
+func MapKeyString(key reflect.Value) (string, error) {
+	switch {
+	case key.Kind() == reflect.String:
+		return key.String(), nil
+	case key.CanInt():
+		return strconv.FormatInt(key.Int(), 10), nil
+	case key.CanUint():
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("map keys of type %s are not supported", key.Type())
+}
+
+// ParseMapKey converts a saved key back to a key of type t
+func ParseMapKey(s string, t reflect.Type) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	switch {
+	case t.Kind() == reflect.String:
+		key.SetString(s)
+	case key.CanInt():
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return key, fmt.Errorf("map key %q: %w", s, err)
+		}
+		key.SetInt(i)
+	case key.CanUint():
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return key, fmt.Errorf("map key %q: %w", s, err)
+		}
+		key.SetUint(u)
+	default:
+		return key, fmt.Errorf("map keys of type %s are not supported", t)
+	}
+	return key, nil
+}
+
+// mapKeyField is the pretend struct field for the value of key in a map
+// held by mapField.  It carries the map field's tags, so that `inline`
+// applies to the values.
+func mapKeyField(mapField *reflect.StructField, mapType reflect.Type, key string) *reflect.StructField {
+	sf := &reflect.StructField{Name: key, Type: mapType.Elem()}
+	if mapField != nil {
+		sf.Tag = mapField.Tag
+	}
+	return sf
+}
+
+// sortedMapKeys returns the keys of m in order, so that walks of maps are
+// deterministic
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// removedMapKeys returns the keys of the map at path that overrides covers
+// but that saved, the map in Common format, does not hold.  These are the
+// keys a child removed from its parent's map.
+func removedMapKeys(overrides *childOverrides, path string, saved map[string]any) []string {
+	var removed []string
+	for p := range overrides.overrides {
+		key, ok := strings.CutPrefix(p, path+".")
+		if !ok || strings.ContainsAny(key, ".[") {
+			continue
+		}
+		if _, held := saved[key]; !held {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
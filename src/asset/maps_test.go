@@ -0,0 +1,151 @@
+package asset_test
+
+import (
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapAsset struct {
+	Stats  map[string]float64
+	Levels map[int]string
+	Drops  map[string]*depLeaf
+	Parts  map[string]*depLeaf `flat:"inline"`
+	Blobs  map[uint8][]byte
+}
+
+func TestMapRoundTrip(t *testing.T) {
+	for _, format := range []asset.SaveFormat{asset.SaveFormatJSON, asset.SaveFormatBinary} {
+		wfs := newWriteFS()
+		m := newTestManager(wfs, mapAsset{}, depLeaf{})
+		m.SetSaveFormat(format)
+
+		leaf := &depLeaf{Image: "raw/leaf.png"}
+		assert.NoError(t, m.Save("leaf.json", leaf))
+		original := &mapAsset{
+			Stats:  map[string]float64{"hp": 10, "speed": 2.5},
+			Levels: map[int]string{1: "one", -2: "minus two"},
+			Drops:  map[string]*depLeaf{"common": leaf},
+			Parts:  map[string]*depLeaf{"engine": {Image: "raw/engine.png"}},
+			Blobs:  map[uint8][]byte{7: {1, 2, 3}},
+		}
+		assert.NoError(t, m.Save("maps.json", original))
+		assert.Equal(t, []asset.Path{"leaf.json", "raw/engine.png"}, m.Dependencies("maps.json"))
+
+		fresh := newTestManager(wfs, mapAsset{}, depLeaf{})
+		loaded, err := fresh.Load("maps.json")
+		assert.NoError(t, err)
+		loadedLeaf, err := fresh.Load("leaf.json")
+		assert.NoError(t, err)
+		loadedMaps := loaded.(*mapAsset)
+		assert.True(t, loadedMaps.Drops["common"] == loadedLeaf, "references load as the shared asset")
+		loadedMaps.Drops = original.Drops
+		assert.Equal(t, original, loadedMaps)
+	}
+}
+
+func TestMapChildOverrides(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, mapAsset{}, depLeaf{})
+
+	parent := &mapAsset{Stats: map[string]float64{"hp": 10, "speed": 2}}
+	assert.NoError(t, m.Save("parent.json", parent))
+	child := &mapAsset{Stats: map[string]float64{"hp": 10, "speed": 5, "armour": 1}}
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.True(t, m.ChildOverridesField(child, "Stats.speed"))
+	assert.True(t, m.ChildOverridesField(child, "Stats.armour"))
+	assert.False(t, m.ChildOverridesField(child, "Stats.hp"))
+	assert.NoError(t, m.Save("child.json", child))
+
+	container := readContainer(t, wfs, "child.json")
+	assert.Equal(t, js{"speed": float64(5), "armour": float64(1)}, container["Inner"].(js)["Stats"])
+
+	// keys the child does not override follow the parent
+	parent.Stats["hp"] = 20
+	parent.Stats["speed"] = 3
+	assert.NoError(t, m.Save("parent.json", parent))
+	assert.Equal(t, map[string]float64{"hp": 20, "speed": 5, "armour": 1}, child.Stats)
+
+	fresh := newTestManager(wfs, mapAsset{}, depLeaf{})
+	loaded, err := fresh.Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"hp": 20, "speed": 5, "armour": 1}, loaded.(*mapAsset).Stats)
+	assert.True(t, fresh.ChildOverridesField(loaded, "Stats.speed"))
+	assert.False(t, fresh.ChildOverridesField(loaded, "Stats.hp"))
+}
+
+func TestMapLoadErrors(t *testing.T) {
+	wfs := newWriteFS()
+	wfs.WriteFile("bad.json", []byte(`{
+		"Type": "github.com/bradbev/flatland/src/asset_test.mapAsset",
+		"Inner": {
+			"Levels": {"1": "one", "two": "2"},
+			"Stats": {"hp": "lots"}
+		}
+	}`))
+	m := newTestManager(wfs, mapAsset{}, depLeaf{})
+	loaded, err := m.Load("bad.json")
+	var fields []string
+	for _, loadErr := range asset.LoadErrors(err) {
+		fields = append(fields, loadErr.Field)
+	}
+	assert.Equal(t, []string{"Stats.hp", "Levels.two"}, fields)
+	assert.Equal(t, map[int]string{1: "one"}, loaded.(*mapAsset).Levels)
+}
+
+func TestMapChildRemovesKeys(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, mapAsset{}, depLeaf{})
+
+	parent := &mapAsset{
+		Stats:  map[string]float64{"hp": 10, "speed": 2},
+		Levels: map[int]string{1: "one"},
+	}
+	assert.NoError(t, m.Save("parent.json", parent))
+	child := &mapAsset{Stats: map[string]float64{"hp": 10}, Levels: map[int]string{}}
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.NoError(t, m.Save("child.json", child))
+
+	container := readContainer(t, wfs, "child.json")
+	assert.Equal(t, js{"Stats": js{"speed": nil}, "Levels": js{"1": nil}}, container["Inner"])
+
+	fresh := newTestManager(wfs, mapAsset{}, depLeaf{})
+	loaded, err := fresh.Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"hp": 10}, loaded.(*mapAsset).Stats)
+	assert.Equal(t, map[int]string{}, loaded.(*mapAsset).Levels)
+	assert.True(t, fresh.ChildOverridesField(loaded, "Stats.speed"))
+
+	// the removed keys stay removed when the parent changes
+	loadedParent, err := fresh.Load("parent.json")
+	assert.NoError(t, err)
+	loadedParent.(*mapAsset).Stats["hp"] = 20
+	assert.NoError(t, fresh.Save("parent.json", loadedParent))
+	assert.Equal(t, map[string]float64{"hp": 20}, loaded.(*mapAsset).Stats)
+
+	// reverting the removal brings the parent's key back
+	assert.NoError(t, fresh.RevertOverrides(loaded, []string{"Stats.speed"}))
+	assert.Equal(t, map[string]float64{"hp": 20, "speed": 2}, loaded.(*mapAsset).Stats)
+
+	// as the editor removes a key
+	delete(loaded.(*mapAsset).Stats, "hp")
+	assert.NoError(t, fresh.SetChildOverrideForField(loaded, "Stats.hp", asset.OverrideEnable))
+	assert.NoError(t, fresh.Save("parent.json", loadedParent))
+	assert.Equal(t, map[string]float64{"speed": 2}, loaded.(*mapAsset).Stats)
+}
+
+func TestMapReloadRemovesKeys(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, mapAsset{}, depLeaf{})
+	assert.NoError(t, m.Save("maps.json", &mapAsset{Stats: map[string]float64{"hp": 10, "speed": 2}}))
+
+	fresh := newTestManager(wfs, mapAsset{}, depLeaf{})
+	loaded, err := fresh.Load("maps.json")
+	assert.NoError(t, err)
+	assert.NoError(t, m.Save("maps.json", &mapAsset{Stats: map[string]float64{"hp": 5}}))
+	reloaded, err := fresh.LoadWithOptions("maps.json", asset.LoadOptions{ForceReload: true})
+	assert.NoError(t, err)
+	assert.True(t, reloaded == loaded)
+	assert.Equal(t, map[string]float64{"hp": 5}, loaded.(*mapAsset).Stats)
+}
@@ -0,0 +1,235 @@
+package asset
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+)
+
+// MigrationFunc upgrades the saved fields of an asset by one version.
+// inner is the asset in Common format, a map of field name to value,
+// and should be changed in place.  Child assets only store the fields
+// that differ from their parent, so a migration must cope with any field
+// being missing.
+type MigrationFunc func(inner map[string]any) error
+
+// RegisterMigration registers a migration that upgrades saved assets of
+// zeroAsset's type from fromVersion to fromVersion+1.  The current version
+// of a type is one more than the highest fromVersion registered, types
+// without migrations are version 0.  Assets are always saved at the
+// current version and migrated when they are loaded.
+//
+// For example, renaming Ship.MaxVelocity to Ship.MaxSpeed
+//
+//	asset.RegisterMigration(Ship{}, 0, func(inner map[string]any) error {
+//		if v, ok := inner["MaxVelocity"]; ok {
+//			inner["MaxSpeed"] = v
+//			delete(inner, "MaxVelocity")
+//		}
+//		return nil
+//	})
+func (a *Manager) RegisterMigration(zeroAsset any, fromVersion int, migrate MigrationFunc) {
+	_, typeName := ObjectTypeName(zeroAsset)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Migrations[typeName] == nil {
+		a.Migrations[typeName] = map[int]MigrationFunc{}
+	}
+	a.Migrations[typeName][fromVersion] = migrate
+}
+
+// currentVersion returns the version that assets of typeName are saved at
+func (a *Manager) currentVersion(typeName string) int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	version := 0
+	for from := range a.Migrations[typeName] {
+		if from+1 > version {
+			version = from + 1
+		}
+	}
+	return version
+}
+
+func (a *Manager) migration(typeName string, fromVersion int) MigrationFunc {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Migrations[typeName][fromVersion]
+}
+
+// migrateContainer runs the migrations needed to bring container up to
+// the current version of its type.  Inline assets are containers of their
+// own and are migrated when they load.
+func (a *Manager) migrateContainer(container *onDiskSaveFormat) error {
+	current := a.currentVersion(container.Type)
+	if container.Version > current {
+		return fmt.Errorf("%s is version %d, but only version %d is known", container.Type, container.Version, current)
+	}
+	if container.Version == current {
+		return nil
+	}
+
+	inner, isMap := container.Inner.(map[string]any)
+	if container.Inner == nil {
+		// a child that inherits everything, the migrations may still add fields
+		inner, isMap = map[string]any{}, true
+	}
+	if !isMap {
+		return fmt.Errorf("%s cannot be migrated, saved data is not a struct", container.Type)
+	}
+
+	for version := container.Version; version < current; version++ {
+		migrate := a.migration(container.Type, version)
+		if migrate == nil {
+			return fmt.Errorf("%s has no migration from version %d", container.Type, version)
+		}
+		if err := migrate(inner); err != nil {
+			return fmt.Errorf("%s migration from version %d failed: %w", container.Type, version, err)
+		}
+	}
+
+	container.Version = current
+	if container.Inner != nil || len(inner) > 0 {
+		container.Inner = inner
+	}
+	return nil
+}
+
+// migrateAllContainers migrates container and every inline container
+// inside of it.  It returns true if anything was changed.
+func (a *Manager) migrateAllContainers(container *onDiskSaveFormat) (bool, error) {
+	before := container.Version
+	if err := a.migrateContainer(container); err != nil {
+		return false, err
+	}
+	changed := before != container.Version
+
+	desc := a.descriptorForTypeName(container.Type)
+	if desc == nil {
+		return changed, nil
+	}
+	innerChanged, err := a.migrateInlineContainers(desc.Type, container.Inner)
+	return changed || innerChanged, err
+}
+
+func (a *Manager) migrateInlineContainers(t reflect.Type, v any) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	changed := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false, nil
+		}
+		if _, hasInner := m["Inner"]; !hasInner {
+			return false, nil
+		}
+		inline := containerFromCommonFormat(m)
+		changed, err := a.migrateAllContainers(inline)
+		if err != nil || !changed {
+			return false, err
+		}
+		m["Version"] = inline.Version
+		m["Inner"] = inline.Inner
+		return true, nil
+	case reflect.Struct:
+		if m, ok := v.(map[string]any); ok {
+			for k, fieldValue := range m {
+				if sf, ok := t.FieldByName(k); ok {
+					c, err := a.migrateInlineContainers(sf.Type, fieldValue)
+					if err != nil {
+						return false, err
+					}
+					changed = changed || c
+				}
+			}
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]any); ok {
+			for _, itemValue := range m {
+				c, err := a.migrateInlineContainers(t.Elem(), itemValue)
+				if err != nil {
+					return false, err
+				}
+				changed = changed || c
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if diff, isDiff := asSliceDiff(v); isDiff {
+			// a child's changes to its parent's elements
+			for _, item := range diff.Elements {
+				items = append(items, item)
+			}
+			ok = true
+		}
+		if ok {
+			for _, item := range items {
+				c, err := a.migrateInlineContainers(t.Elem(), item)
+				if err != nil {
+					return false, err
+				}
+				changed = changed || c
+			}
+		}
+	}
+	return changed, nil
+}
+
+// Upgrade migrates every asset that the Manager can read to the current
+// version of its type and writes it back with the writable file system,
+// keeping the format (json or binary) it was saved in.  The paths of the
+// assets that changed are returned.
+func (a *Manager) Upgrade() ([]Path, error) {
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	a.mu.RUnlock()
+	if writeFS == nil {
+		return nil, fmt.Errorf("Upgrade needs a writable file system")
+	}
+
+	var upgraded []Path
+	err := a.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := a.ReadFile(Path(path))
+		if err != nil {
+			return err
+		}
+		container, err := decodeContainer(data)
+		if err != nil || container.Type == "" {
+			// not an asset
+			return nil
+		}
+		changed, err := a.migrateAllContainers(container)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !changed {
+			return nil
+		}
+
+		format := SaveFormatJSON
+		if isBinaryFormat(data) {
+			format = SaveFormatBinary
+		}
+		data, err = a.encodeContainer(container, format)
+		if err != nil {
+			return err
+		}
+		if err := writeFS.WriteFile(Path(path), data); err != nil {
+			return err
+		}
+		a.wrote(Path(path))
+		upgraded = append(upgraded, Path(path))
+		return nil
+	})
+	return upgraded, err
+}
@@ -0,0 +1,144 @@
+package asset_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type migrateShip struct {
+	MaxSpeed float64
+	Name     string
+}
+
+type migrateHangar struct {
+	Ship *migrateShip `flat:"inline"`
+}
+
+func registerShipMigrations(m *asset.Manager) {
+	// version 0 -> 1 renamed MaxVelocity to MaxSpeed
+	m.RegisterMigration(migrateShip{}, 0, func(inner map[string]any) error {
+		if v, ok := inner["MaxVelocity"]; ok {
+			inner["MaxSpeed"] = v
+			delete(inner, "MaxVelocity")
+		}
+		return nil
+	})
+	// version 1 -> 2 gave ships a default name
+	m.RegisterMigration(migrateShip{}, 1, func(inner map[string]any) error {
+		if _, ok := inner["Name"]; !ok {
+			inner["Name"] = "unnamed"
+		}
+		return nil
+	})
+}
+
+const oldShip = `{
+	"Type": "github.com/bradbev/flatland/src/asset_test.migrateShip",
+	"Inner": {
+		"MaxVelocity": 12
+	}
+}`
+
+const oldHangar = `{
+	"Type": "github.com/bradbev/flatland/src/asset_test.migrateHangar",
+	"Inner": {
+		"Ship": {
+			"Type": "github.com/bradbev/flatland/src/asset_test.migrateShip",
+			"Inner": {
+				"MaxVelocity": 3
+			}
+		}
+	}
+}`
+
+func newMigrationManager() (*asset.Manager, *writeFS) {
+	wfs := newWriteFS()
+	wfs.WriteFile("ship.json", []byte(oldShip))
+	wfs.WriteFile("hangar.json", []byte(oldHangar))
+	wfs.WriteFile("image.png", []byte("not an asset"))
+
+	m := asset.NewManager()
+	m.RegisterAsset(migrateShip{})
+	m.RegisterAsset(migrateHangar{})
+	registerShipMigrations(m)
+	m.RegisterFileSystem(wfs.fs, 0)
+	m.RegisterWritableFileSystem(wfs)
+	return m, wfs
+}
+
+func TestMigrationOnLoad(t *testing.T) {
+	m, _ := newMigrationManager()
+
+	ship, err := m.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &migrateShip{MaxSpeed: 12, Name: "unnamed"}, ship)
+
+	hangar, err := m.Load("hangar.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &migrateHangar{Ship: &migrateShip{MaxSpeed: 3, Name: "unnamed"}}, hangar)
+}
+
+func TestMigrationSaveVersion(t *testing.T) {
+	m, wfs := newMigrationManager()
+	assert.NoError(t, m.Save("saved.json", &migrateShip{MaxSpeed: 1, Name: "saved"}))
+
+	data, err := fs.ReadFile(wfs.fs, "saved.json")
+	assert.NoError(t, err)
+	container := js{}
+	assert.NoError(t, json.Unmarshal(data, &container))
+	assert.Equal(t, float64(2), container["Version"])
+
+	// loading an asset saved by a newer version of the game must fail
+	// rather than silently lose data
+	older := asset.NewManager()
+	older.RegisterAsset(migrateShip{})
+	older.RegisterFileSystem(wfs.fs, 0)
+	_, err = older.Load("saved.json")
+	assert.Error(t, err)
+}
+
+func TestUpgrade(t *testing.T) {
+	m, wfs := newMigrationManager()
+
+	upgraded, err := m.Upgrade()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []asset.Path{"ship.json", "hangar.json"}, upgraded)
+
+	data, err := fs.ReadFile(wfs.fs, "hangar.json")
+	assert.NoError(t, err)
+	container := js{}
+	assert.NoError(t, json.Unmarshal(data, &container))
+	expected := js{
+		"Type":   "github.com/bradbev/flatland/src/asset_test.migrateHangar",
+		"Parent": "",
+		"Inner": js{
+			"Ship": js{
+				"Type":    "github.com/bradbev/flatland/src/asset_test.migrateShip",
+				"Version": float64(2),
+				"Inner": js{
+					"MaxSpeed": float64(3),
+					"Name":     "unnamed",
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, container)
+
+	// everything is current, nothing more to do
+	upgraded, err = m.Upgrade()
+	assert.NoError(t, err)
+	assert.Empty(t, upgraded)
+
+	// a manager without the migrations can load the upgraded files
+	fresh := asset.NewManager()
+	fresh.RegisterAsset(migrateShip{})
+	fresh.RegisterMigration(migrateShip{}, 1, func(map[string]any) error { return nil })
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	ship, err := fresh.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &migrateShip{MaxSpeed: 12, Name: "unnamed"}, ship)
+}
@@ -0,0 +1,206 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"golang.org/x/exp/maps"
+)
+
+// Move renames the file at oldPath to newPath.  Every asset that refers to
+// oldPath (as a reference, a Parent or an asset.Path field) is rewritten on
+// disk to refer to newPath, and loaded assets are updated to match.
+// oldPath may be any file, not only an asset.
+func (a *Manager) Move(oldPath, newPath Path) error {
+	if oldPath == newPath {
+		return nil
+	}
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	a.mu.RUnlock()
+	if writeFS == nil {
+		return fmt.Errorf("Move needs a writable file system")
+	}
+	if _, err := a.ReadFile(oldPath); err != nil {
+		return err
+	}
+	movedID := a.idAtPath(oldPath)
+	if _, err := a.ReadFile(newPath); err == nil {
+		return fmt.Errorf("unable to move %s, %s already exists", oldPath, newPath)
+	}
+
+	referencers := a.Referencers(oldPath)
+	if err := writeFS.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	a.wrote(oldPath, newPath)
+
+	a.mu.Lock()
+	if loaded, ok := a.LoadPathToAsset[oldPath]; ok {
+		delete(a.LoadPathToAsset, oldPath)
+		a.LoadPathToAsset[newPath] = loaded
+		a.AssetToLoadPath[loaded] = newPath
+		if err, ok := a.LoadErrors[oldPath]; ok {
+			delete(a.LoadErrors, oldPath)
+			a.LoadErrors[newPath] = err
+		}
+	}
+	for child, parentPath := range a.ChildToParent {
+		if parentPath == oldPath {
+			a.ChildToParent[child] = newPath
+		}
+	}
+	a.dependencies = nil
+	a.mu.Unlock()
+
+	var errs []error
+	for _, referencer := range referencers {
+		if err := a.rewriteReferences(writeFS, referencer, oldPath, newPath, movedID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", referencer, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rewriteReferences changes the references to oldPath, or to the asset
+// with movedID, in the asset file at path to newPath.  If the asset is
+// loaded its asset.Path fields are changed in memory too.  It is not
+// reloaded, that would throw away edits to it that are not saved yet.
+func (a *Manager) rewriteReferences(writeFS WriteableFileSystem, path, oldPath, newPath Path, movedID string) error {
+	data, err := a.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	container, err := decodeContainer(data)
+	if err != nil {
+		return err
+	}
+	changed := a.visitContainerReferences(container, func(ref reference) reference {
+		if ref.Path == oldPath || (ref.ID != "" && ref.ID == movedID) {
+			ref.Path = newPath
+		}
+		return ref
+	})
+	if !changed {
+		return nil
+	}
+
+	format := SaveFormatJSON
+	if isBinaryFormat(data) {
+		format = SaveFormatBinary
+	}
+	data, err = a.encodeContainer(container, format)
+	if err != nil {
+		return err
+	}
+	if err := writeFS.WriteFile(path, data); err != nil {
+		return err
+	}
+	a.wrote(path)
+
+	a.mu.RLock()
+	loaded, ok := a.LoadPathToAsset[path]
+	assets := maps.Clone(a.AssetToLoadPath)
+	a.mu.RUnlock()
+	if ok {
+		w := &pathRewriter{root: loaded, loaded: assets, oldPath: oldPath, newPath: newPath, seen: map[ownedKey]bool{}}
+		w.rewrite(reflect.ValueOf(loaded))
+	}
+	return nil
+}
+
+// pathRewriter changes the asset.Path values that the root asset owns from
+// oldPath to newPath.  Like ownedWalker it stops at other loaded assets,
+// references to the moved asset are pointers and need no change.
+type pathRewriter struct {
+	root             Asset
+	loaded           map[Asset]Path
+	oldPath, newPath Path
+	seen             map[ownedKey]bool
+}
+
+func (w *pathRewriter) rewrite(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == pathType && Path(v.String()) == w.oldPath && v.CanSet() {
+			v.SetString(string(w.newPath))
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := ownedKey{v.Pointer(), v.Type()}
+		if w.seen[key] {
+			return
+		}
+		w.seen[key] = true
+		if v.CanInterface() {
+			value := v.Interface()
+			if _, isAsset := w.loaded[value]; isAsset && value != w.root {
+				return
+			}
+		}
+		w.rewrite(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		inner := v.Elem()
+		if inner.Kind() == reflect.Pointer {
+			w.rewrite(inner)
+			return
+		}
+		// values held directly by an interface can't be set, change a copy
+		if v.CanSet() {
+			c := reflect.New(inner.Type()).Elem()
+			c.Set(inner)
+			w.rewrite(c)
+			v.Set(c)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			sf := t.Field(i)
+			if IsSavedField(&sf) {
+				w.rewrite(FieldValue(v, i))
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			w.rewrite(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values can't be set in place, change a copy
+			c := reflect.New(v.Type().Elem()).Elem()
+			c.Set(iter.Value())
+			w.rewrite(c)
+			v.SetMapIndex(iter.Key(), c)
+		}
+	}
+}
+
+// Delete removes the file at path.  Assets that refer to path are not
+// changed, use Referencers to find them first.
+func (a *Manager) Delete(path Path) error {
+	a.mu.RLock()
+	writeFS := a.WriteFS
+	a.mu.RUnlock()
+	if writeFS == nil {
+		return fmt.Errorf("Delete needs a writable file system")
+	}
+	if err := writeFS.Remove(path); err != nil {
+		return err
+	}
+	a.wrote(path)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if loaded, ok := a.LoadPathToAsset[path]; ok {
+		a.forget(path, loaded)
+	}
+	a.dependencies = nil
+	return nil
+}
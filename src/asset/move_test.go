@@ -0,0 +1,85 @@
+package asset_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+func readContainer(t *testing.T, wfs *writeFS, path string) js {
+	data, err := fs.ReadFile(wfs.fs, path)
+	assert.NoError(t, err)
+	container := js{}
+	assert.NoError(t, json.Unmarshal(data, &container))
+	return container
+}
+
+func TestMove(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+	wfs.WriteFile("raw/leaf.png", []byte("png"))
+
+	leaf := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	child := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.SetParent(child, leaf))
+	assert.NoError(t, m.Save("child.json", child))
+	root := &depRoot{Ref: leaf, Inline: &depLeaf{Image: "raw/leaf.png"}}
+	assert.NoError(t, m.Save("root.json", root))
+
+	assert.NoError(t, m.Move("leaf.json", "moved/leaf.json"))
+
+	_, err := fs.Stat(wfs.fs, "leaf.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, "moved/leaf.json", readContainer(t, wfs, "child.json")["Parent"])
+	ref := readContainer(t, wfs, "root.json")["Inner"].(js)["Ref"].(js)
+	assert.Equal(t, "moved/leaf.json", ref["Path"])
+
+	path, err := m.GetLoadPathForAsset(leaf)
+	assert.NoError(t, err)
+	assert.Equal(t, asset.Path("moved/leaf.json"), path)
+	assert.Equal(t, asset.Path("moved/leaf.json"), m.GetParent(child))
+	loaded, err := m.Load("moved/leaf.json")
+	assert.NoError(t, err)
+	assert.True(t, loaded == leaf, "the loaded asset must move with its file")
+	assert.Equal(t, []asset.Path{"child.json", "root.json"}, m.Referencers("moved/leaf.json"))
+
+	// moving a raw file fixes the asset.Path fields, including in memory
+	assert.NoError(t, m.Move("raw/leaf.png", "images/leaf.png"))
+	assert.Equal(t, "images/leaf.png", readContainer(t, wfs, "moved/leaf.json")["Inner"].(js)["Image"])
+	assert.Equal(t, asset.Path("images/leaf.png"), leaf.Image)
+	assert.Equal(t, asset.Path("images/leaf.png"), root.Inline.Image)
+
+	// referencers are changed in place, edits that are not saved are kept
+	root.Images = []asset.Path{"images/leaf.png", "unsaved.png"}
+	assert.NoError(t, m.Move("images/leaf.png", "raw/leaf.png"))
+	assert.Equal(t, []asset.Path{"raw/leaf.png", "unsaved.png"}, root.Images)
+	assert.Equal(t, asset.Path("raw/leaf.png"), root.Inline.Image)
+	assert.True(t, root.Ref == leaf)
+
+	// the destination must not exist
+	assert.Error(t, m.Move("child.json", "root.json"))
+	assert.Error(t, m.Move("missing.json", "other.json"))
+}
+
+func TestDelete(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+	leaf := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	assert.NoError(t, m.Save("root.json", &depRoot{Ref: leaf, Inline: &depLeaf{}}))
+	assert.Equal(t, []asset.Path{"root.json"}, m.Referencers("leaf.json"))
+
+	assert.NoError(t, m.Delete("root.json"))
+	_, err := fs.Stat(wfs.fs, "root.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Empty(t, m.Referencers("leaf.json"))
+
+	assert.NoError(t, m.Delete("leaf.json"))
+	_, err = m.GetLoadPathForAsset(leaf)
+	assert.Error(t, err)
+	assert.Error(t, m.Delete("leaf.json"))
+}
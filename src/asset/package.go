@@ -0,0 +1,99 @@
+package asset
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Reachable returns the files that the assets at roots need, in path
+// order.  That is the roots, their parents, and every asset and file
+// (images, fonts, etc) that they refer to, followed recursively.  Files
+// that are referred to but cannot be read are reported in the error, the
+// files that were found are still returned.
+func (a *Manager) Reachable(roots ...Path) ([]Path, error) {
+	found := map[Path]struct{}{}
+	ids := a.dependencyIndex().ids
+	var errs []error
+	var visit func(from, path Path)
+	visit = func(from, path Path) {
+		if _, seen := found[path]; seen {
+			return
+		}
+		data, err := a.ReadFile(path)
+		if err != nil {
+			if from == "" {
+				errs = append(errs, fmt.Errorf("missing root %s", path))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: missing %s", from, path))
+			}
+			return
+		}
+		found[path] = struct{}{}
+		if !strings.HasSuffix(string(path), ".json") {
+			return
+		}
+		container, err := decodeContainer(data)
+		if err != nil || container.Type == "" {
+			// not an asset, so it has no references
+			return
+		}
+		var refs []Path
+		a.collectContainerDependencies(container, ids, func(to Path) {
+			refs = append(refs, to)
+		})
+		sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+		for _, ref := range refs {
+			visit(path, ref)
+		}
+	}
+	for _, root := range roots {
+		visit("", root)
+	}
+	return sortedPaths(found), errors.Join(errs...)
+}
+
+// Package writes the files that the assets at roots need (see Reachable)
+// to dest, unchanged.  Nothing is written if any of them are missing.  The
+// paths that were written are returned.
+func (a *Manager) Package(dest WriteableFileSystem, roots ...Path) ([]Path, error) {
+	return a.packageFiles(roots, dest.WriteFile)
+}
+
+// PackageArchive is Package, but writes the files to w as a single zip
+// archive.  Use OpenArchive to read it back.
+func (a *Manager) PackageArchive(w io.Writer, roots ...Path) ([]Path, error) {
+	archive := zip.NewWriter(w)
+	paths, err := a.packageFiles(roots, func(path Path, data []byte) error {
+		f, err := archive.Create(string(path))
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, archive.Close()
+}
+
+func (a *Manager) packageFiles(roots []Path, write func(Path, []byte) error) ([]Path, error) {
+	paths, err := a.Reachable(roots...)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := a.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(path, data); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,66 @@
+package asset_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackage(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, depLeaf{}, depRoot{})
+
+	for _, file := range []asset.Path{"raw/leaf.png", "raw/parent.png", "raw/inline.png", "raw/listed.png", "raw/unused.png"} {
+		wfs.WriteFile(file, []byte(file))
+	}
+	parent := &depLeaf{Image: "raw/parent.png"}
+	assert.NoError(t, m.Save("parent.json", parent))
+	leaf := &depLeaf{Image: "raw/leaf.png"}
+	assert.NoError(t, m.SetParent(leaf, parent))
+	assert.NoError(t, m.Save("leaf.json", leaf))
+	root := &depRoot{
+		Ref:    leaf,
+		Inline: &depLeaf{Image: "raw/inline.png"},
+		Images: []asset.Path{"raw/listed.png"},
+	}
+	assert.NoError(t, m.Save("root.json", root))
+	assert.NoError(t, m.Save("unused.json", &depLeaf{Image: "raw/unused.png"}))
+
+	expected := []asset.Path{"leaf.json", "parent.json", "raw/inline.png", "raw/leaf.png", "raw/listed.png", "raw/parent.png", "root.json"}
+	reachable, err := m.Reachable("root.json")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, reachable)
+
+	// a directory
+	out := newWriteFS()
+	written, err := m.Package(out, "root.json")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, written)
+	assert.Len(t, out.fs, len(expected))
+
+	// an archive, which loads without the original content
+	var archive bytes.Buffer
+	_, err = m.PackageArchive(&archive, "root.json")
+	assert.NoError(t, err)
+	fsys, err := asset.OpenArchive(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	assert.NoError(t, err)
+	packaged := asset.NewManager()
+	packaged.RegisterAsset(depLeaf{})
+	packaged.RegisterAsset(depRoot{})
+	packaged.RegisterFileSystem(fsys, 0)
+	loaded, err := packaged.Load("root.json")
+	assert.NoError(t, err)
+	assert.Equal(t, asset.Path("raw/leaf.png"), loaded.(*depRoot).Ref.Image)
+	_, err = packaged.ReadFile("raw/unused.png")
+	assert.Error(t, err)
+
+	// missing files fail the package
+	wfs.Remove("raw/listed.png")
+	_, err = m.Reachable("root.json", "nothere.json")
+	assert.EqualError(t, err, "root.json: missing raw/listed.png\nmissing root nothere.json")
+	written, err = m.Package(newWriteFS(), "root.json")
+	assert.Error(t, err)
+	assert.Nil(t, written)
+}
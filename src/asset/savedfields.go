@@ -0,0 +1,49 @@
+package asset
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Exported fields are saved and loaded.  Unexported fields are not, unless
+// they have a `flat:"save"` tag, which keeps state such as a ship's
+// velocity private to its package while still loading it from data.
+//
+//	type Ship struct {
+//		Name     string
+//		velocity Vec2 `flat:"save"`
+//	}
+//
+// Go's reflect package can read unexported fields but not set them, so
+// saved unexported fields are reached through their address with
+// FieldValue.
+
+// IsSavedField reports whether the struct field sf is saved and loaded
+func IsSavedField(sf *reflect.StructField) bool {
+	if sf.IsExported() {
+		return true
+	}
+	_, save := GetFlatTag(sf, "save")
+	return save
+}
+
+// FieldValue returns field i of the struct v.  Unexported fields with a
+// `flat:"save"` tag can be read and, when v is addressable, set like an
+// exported field.  Other unexported fields are returned as v.Field(i).
+func FieldValue(v reflect.Value, i int) reflect.Value {
+	field := v.Field(i)
+	if field.CanInterface() {
+		return field
+	}
+	sf := v.Type().Field(i)
+	if !IsSavedField(&sf) {
+		return field
+	}
+	if !v.CanAddr() {
+		// the value can only be read, read it from a copy
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		field = c.Field(i)
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,64 @@
+package asset_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type privateVec struct {
+	X, Y float64
+}
+
+type privateShip struct {
+	Name     string
+	velocity privateVec `flat:"save"`
+	lives    int        `flat:"save"`
+	scratch  string
+}
+
+func TestSavedUnexportedFields(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, privateShip{})
+
+	ship := &privateShip{Name: "ship", velocity: privateVec{X: 1, Y: 2}, lives: 3, scratch: "not saved"}
+	assert.NoError(t, m.Save("ship.json", ship))
+	inner := readContainer(t, wfs, "ship.json")["Inner"].(js)
+	assert.Equal(t, js{
+		"Name":     "ship",
+		"velocity": js{"X": float64(1), "Y": float64(2)},
+		"lives":    float64(3),
+	}, inner)
+
+	child := &privateShip{Name: "ship", velocity: privateVec{X: 1, Y: 2}, lives: 5}
+	assert.NoError(t, m.SetParent(child, ship))
+	assert.NoError(t, m.Save("child.json", child))
+	assert.Equal(t, js{"lives": float64(5)}, readContainer(t, wfs, "child.json")["Inner"])
+
+	fresh := asset.NewManager()
+	fresh.RegisterAsset(privateShip{})
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	loaded, err := fresh.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &privateShip{Name: "ship", velocity: privateVec{X: 1, Y: 2}, lives: 3}, loaded)
+	loaded, err = fresh.Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &privateShip{Name: "ship", velocity: privateVec{X: 1, Y: 2}, lives: 5}, loaded)
+}
+
+func TestFieldValue(t *testing.T) {
+	ship := privateShip{lives: 3, scratch: "scratch"}
+	v := reflect.ValueOf(&ship).Elem()
+
+	lives := asset.FieldValue(v, 2)
+	assert.Equal(t, 3, lives.Interface())
+	lives.SetInt(4)
+	assert.Equal(t, 4, ship.lives)
+
+	// fields without the tag stay out of reach
+	assert.False(t, asset.FieldValue(v, 3).CanInterface())
+	// a copy of the struct can be read
+	assert.Equal(t, 4, asset.FieldValue(reflect.ValueOf(ship), 2).Interface())
+}
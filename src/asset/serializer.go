@@ -0,0 +1,101 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// A serializer saves the values of one type in a compact form of its own
+// choosing, rather than field by field.  For example
+//
+//	asset.RegisterSerializer(
+//		func(d time.Duration) any { return d.String() },
+//		func(v any) (time.Duration, error) {
+//			s, _ := v.(string)
+//			return time.ParseDuration(s)
+//		})
+//
+// Serialized values are leaves.  A child asset saves the whole value when
+// it differs from its parent's, and overrides all of it.
+type serializer struct {
+	toCommon   func(any) any
+	fromCommon func(any) (any, error)
+}
+
+// RegisterSerializer makes the DefaultManager save values of type T with
+// toCommon and load them with fromCommon.  toCommon returns a value that
+// json can encode, fromCommon receives that value as it loads from json,
+// numbers are float64, lists []any and objects map[string]any.
+func RegisterSerializer[T any](toCommon func(T) any, fromCommon func(any) (T, error)) {
+	RegisterSerializerWithManager(defaultManager, toCommon, fromCommon)
+}
+
+// RegisterSerializerWithManager is RegisterSerializer for a specific
+// Manager
+func RegisterSerializerWithManager[T any](m *Manager, toCommon func(T) any, fromCommon func(any) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Serializers == nil {
+		m.Serializers = map[reflect.Type]*serializer{}
+	}
+	m.Serializers[t] = &serializer{
+		toCommon:   func(v any) any { return toCommon(v.(T)) },
+		fromCommon: func(v any) (any, error) { return fromCommon(v) },
+	}
+}
+
+// serializerFor returns the serializer registered for t, or nil
+func (a *Manager) serializerFor(t reflect.Type) *serializer {
+	if t == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Serializers[t]
+}
+
+// save converts v with the serializer and gives the result the shape it
+// has when it is loaded, so that it compares equal to a loaded value
+func (s *serializer) save(v any) any {
+	commonFormat := s.toCommon(v)
+	data, err := json.Marshal(commonFormat)
+	if err != nil {
+		log.Printf("Serialized %T will not be saved.  %v", v, err)
+		return nil
+	}
+	var loaded any
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil
+	}
+	return loaded
+}
+
+// load converts a saved value back with the serializer and sets dest to it
+func (s *serializer) load(source reflect.Value, dest reflect.Value) error {
+	var saved any
+	if source.IsValid() {
+		saved = source.Interface()
+	}
+	v, err := s.fromCommon(saved)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	if !value.Type().AssignableTo(dest.Type()) {
+		return fmt.Errorf("cannot load %v into %s", v, dest.Type())
+	}
+	dest.Set(value)
+	return nil
+}
+
+// zero is the saved form of the zero value of t, which a value is
+// compared with when there is no parent
+func (s *serializer) zero(t reflect.Type) any {
+	return s.save(reflect.Zero(t).Interface())
+}
@@ -0,0 +1,97 @@
+package asset_test
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type serializedShip struct {
+	Name     string
+	Tint     color.RGBA
+	Cooldown time.Duration
+}
+
+// withTestSerializers registers the color and duration serializers with m
+func withTestSerializers(m *asset.Manager) *asset.Manager {
+	asset.RegisterSerializerWithManager(m,
+		func(c color.RGBA) any { return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A) },
+		func(v any) (color.RGBA, error) {
+			var c color.RGBA
+			s, _ := v.(string)
+			_, err := fmt.Sscanf(s, "#%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A)
+			return c, err
+		})
+	asset.RegisterSerializerWithManager(m,
+		func(d time.Duration) any { return d.String() },
+		func(v any) (time.Duration, error) {
+			s, _ := v.(string)
+			return time.ParseDuration(s)
+		})
+	return m
+}
+
+func TestSerializer(t *testing.T) {
+	wfs := newWriteFS()
+	m := withTestSerializers(newTestManager(wfs, serializedShip{}))
+	ship := &serializedShip{Name: "ship", Tint: color.RGBA{R: 255, A: 255}, Cooldown: 1500 * time.Millisecond}
+	assert.NoError(t, m.Save("ship.json", ship))
+	assert.Equal(t, js{"Name": "ship", "Tint": "#ff0000ff", "Cooldown": "1.5s"}, readContainer(t, wfs, "ship.json")["Inner"])
+
+	// zero values are left out, like other fields
+	assert.NoError(t, m.Save("empty.json", &serializedShip{Name: "empty"}))
+	assert.Equal(t, js{"Name": "empty"}, readContainer(t, wfs, "empty.json")["Inner"])
+
+	// the whole value is saved when a child changes part of it
+	child := &serializedShip{Name: "ship", Tint: color.RGBA{R: 255, G: 128, A: 255}, Cooldown: ship.Cooldown}
+	assert.NoError(t, m.SetParent(child, ship))
+	m.SetChildOverrideForField(child, "Tint.G", asset.OverrideEnable)
+	assert.NoError(t, m.Save("child.json", child))
+	assert.Equal(t, js{"Tint": "#ff8000ff"}, readContainer(t, wfs, "child.json")["Inner"])
+
+	fresh := withTestSerializers(newTestManager(wfs, serializedShip{}))
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	loaded, err := fresh.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, ship, loaded)
+	loaded, err = fresh.Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, child, loaded)
+	assert.True(t, fresh.ChildOverridesField(loaded, "Tint"))
+
+	// a child keeps following its parent's values that it does not override
+	ship.Cooldown = time.Second
+	assert.NoError(t, m.Save("ship.json", ship))
+	reloaded, err := m.Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, reloaded.(*serializedShip).Cooldown)
+}
+
+func TestSerializerBinaryAndCheck(t *testing.T) {
+	wfs := newWriteFS()
+	m := withTestSerializers(newTestManager(wfs, serializedShip{}))
+	m.SetSaveFormat(asset.SaveFormatBinary)
+	ship := &serializedShip{Name: "ship", Tint: color.RGBA{B: 10}, Cooldown: time.Minute}
+	assert.NoError(t, m.Save("ship.json", ship))
+
+	fresh := withTestSerializers(newTestManager(wfs, serializedShip{}))
+	fresh.RegisterFileSystem(wfs.fs, 0)
+	loaded, err := fresh.Load("ship.json")
+	assert.NoError(t, err)
+	assert.Equal(t, ship, loaded)
+
+	wfs.WriteFile("bad.json", []byte(`{
+  "Type": "github.com/bradbev/flatland/src/asset_test.serializedShip",
+  "Parent": "",
+  "Inner": {"Cooldown": "soon"}
+}`))
+	problems := fresh.Check()
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, asset.Path("bad.json"), problems[0].Path)
+		assert.Equal(t, "Cooldown", problems[0].Field)
+	}
+}
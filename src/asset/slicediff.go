@@ -0,0 +1,429 @@
+package asset
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// A child asset saves a slice that its parent also has as a sliceDiff
+// rather than as the whole list, so that it keeps following the parent's
+// elements that it did not change.  Elements are identified by their
+// index, or by the value of one of their fields when the slice field has a
+// `flat:"key:Name"` tag.  Keys stay the same when elements are added,
+// removed or reordered, indexes do not.
+//
+// The elements that a child adds to a slice identified by index come after
+// its parent's, and have the ids "+0", "+1" and so on.  They never take the
+// place of an element that the parent adds later.
+//
+// In Common format a sliceDiff is a map[string]any, the type of the field
+// tells it apart from a list.
+type sliceDiff struct {
+	// Elements are the elements the child changed or added, by id.  Changed
+	// struct elements only hold the fields that changed.
+	Elements map[string]any
+	// Removed are the ids of the parent's elements that the child removed
+	Removed []string
+	// Order is every id in the child's order.  It is only saved when the
+	// child reorders the elements.
+	Order []string
+}
+
+func (d *sliceDiff) empty() bool {
+	return len(d.Elements) == 0 && len(d.Removed) == 0 && d.Order == nil
+}
+
+func (d *sliceDiff) commonFormat() map[string]any {
+	m := map[string]any{}
+	if len(d.Elements) > 0 {
+		m["Elements"] = d.Elements
+	}
+	if len(d.Removed) > 0 {
+		m["Removed"] = d.Removed
+	}
+	if d.Order != nil {
+		m["Order"] = d.Order
+	}
+	return m
+}
+
+// asSliceDiff reads a sliceDiff that was saved in Common format
+func asSliceDiff(v any) (*sliceDiff, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	elements, _ := m["Elements"].(map[string]any)
+	return &sliceDiff{
+		Elements: elements,
+		Removed:  stringList(m["Removed"]),
+		Order:    stringList(m["Order"]),
+	}, true
+}
+
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		result := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// sliceKey returns the name of the field that identifies the elements of
+// the slice held by sf, or "" when elements are identified by index
+func sliceKey(sf *reflect.StructField) string {
+	if sf == nil {
+		return ""
+	}
+	key, _ := GetFlatTag(sf, "key")
+	return key
+}
+
+// addedPrefix starts the ids of the elements that a child added to a slice
+// that is identified by index
+const addedPrefix = "+"
+
+// IsAddedElementID reports whether id is the id of an element that a child
+// added to a slice identified by index
+func IsAddedElementID(id string) bool {
+	return strings.HasPrefix(id, addedPrefix)
+}
+
+// withAddedIDs gives the last added of the index ids their added ids
+func withAddedIDs(ids []string, added int) []string {
+	start := len(ids) - added
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(ids); i++ {
+		ids[i] = addedPrefix + strconv.Itoa(i-start)
+	}
+	return ids
+}
+
+// elementPath is the override path of the element id of the slice at path
+func elementPath(path, id string) string {
+	return path + "[" + id + "]"
+}
+
+// orderPath is the override path that records that a child reordered the
+// slice at path
+func orderPath(path string) string {
+	return path + "[]"
+}
+
+// sliceElementField is the pretend struct field for the element id of a
+// slice held by sliceField.  Like mapKeyField it carries the slice field's
+// tags.
+func sliceElementField(sliceField *reflect.StructField, sliceType reflect.Type, id string) *reflect.StructField {
+	sf := &reflect.StructField{Name: "[" + id + "]", Type: sliceType.Elem()}
+	if sliceField != nil {
+		sf.Tag = sliceField.Tag
+	}
+	return sf
+}
+
+// SliceElementIDs returns the id of each element of the slice held by the
+// struct field sf.  The ids are the element keys for slices with a
+// `flat:"key:Name"` tag, and the indexes otherwise, or when the keys are
+// not unique.
+func SliceElementIDs(sf *reflect.StructField, slice reflect.Value) []string {
+	if ids, ok := elementIDs(slice, sliceKey(sf)); ok {
+		return ids
+	}
+	ids, _ := elementIDs(slice, "")
+	return ids
+}
+
+// elementIDs returns the ids of the elements of slice.  ok is false when
+// the elements are keyed and a key is missing, empty or repeated.
+func elementIDs(slice reflect.Value, key string) (ids []string, ok bool) {
+	ids = make([]string, slice.Len())
+	for i := range ids {
+		if key == "" {
+			ids[i] = strconv.Itoa(i)
+			continue
+		}
+		element := slice.Index(i)
+		for element.Kind() == reflect.Pointer || element.Kind() == reflect.Interface {
+			if element.IsNil() {
+				return nil, false
+			}
+			element = element.Elem()
+		}
+		if element.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field := element.FieldByName(key)
+		if !field.IsValid() || !field.CanInterface() {
+			return nil, false
+		}
+		ids[i] = fmt.Sprint(field.Interface())
+	}
+	return ids, uniqueIDs(ids)
+}
+
+// commonElementIDs is elementIDs for a list in Common format
+func commonElementIDs(list []any, key string) (ids []string, ok bool) {
+	ids = make([]string, len(list))
+	for i, item := range list {
+		if key == "" {
+			ids[i] = strconv.Itoa(i)
+			continue
+		}
+		if container, isContainer := item.(*onDiskSaveFormat); isContainer {
+			item = container.Inner
+		}
+		m, isStruct := item.(map[string]any)
+		if !isStruct {
+			return nil, false
+		}
+		value, hasKey := m[key]
+		if !hasKey {
+			return nil, false
+		}
+		ids[i] = fmt.Sprint(value)
+	}
+	return ids, uniqueIDs(ids)
+}
+
+func uniqueIDs(ids []string) bool {
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, repeated := seen[id]; repeated || id == "" {
+			return false
+		}
+		seen[id] = struct{}{}
+	}
+	return true
+}
+
+// idLess orders indexes numerically, then added ids numerically, and keys
+// as strings
+func idLess(a, b string) bool {
+	if aAdded, bAdded := IsAddedElementID(a), IsAddedElementID(b); aAdded != bAdded {
+		return bAdded
+	}
+	ai, aErr := strconv.Atoi(strings.TrimPrefix(a, addedPrefix))
+	bi, bErr := strconv.Atoi(strings.TrimPrefix(b, addedPrefix))
+	if aErr == nil && bErr == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+// mergedOrder is the order of the ids once diff is applied to a slice
+// whose elements have the ids in base.  Elements that diff.Order does not
+// mention keep their place after the ordered ones, so that a child still
+// picks up the elements its parent adds later.
+func mergedOrder(base []string, diff *sliceDiff) []string {
+	removed := map[string]bool{}
+	for _, id := range diff.Removed {
+		removed[id] = true
+	}
+	exists := map[string]bool{}
+	for _, id := range base {
+		exists[id] = !removed[id]
+	}
+	var added []string
+	for id := range diff.Elements {
+		if _, inBase := exists[id]; !inBase {
+			added = append(added, id)
+			exists[id] = true
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return idLess(added[i], added[j]) })
+
+	order := []string{}
+	placed := map[string]bool{}
+	for _, ids := range [][]string{diff.Order, base, added} {
+		for _, id := range ids {
+			if exists[id] && !placed[id] {
+				order = append(order, id)
+				placed[id] = true
+			}
+		}
+	}
+	return order
+}
+
+// diffSlice returns the sliceDiff, in Common format, that turns the
+// parent's list into the child's, or nil when they are the same.  When the
+// elements cannot be identified the whole child list is returned.
+func (a *Manager) diffSlice(parent, child []any, t reflect.Type, sf *reflect.StructField) any {
+	key := sliceKey(sf)
+	parentIDs, parentOK := commonElementIDs(parent, key)
+	childIDs, childOK := commonElementIDs(child, key)
+	if !parentOK || !childOK {
+		if len(parent) == len(child) && a.findDiffsFromParentCommonFormat(parent, child, nil, nil) == nil {
+			return nil
+		}
+		return child
+	}
+	if key == "" && len(child) > len(parent) {
+		childIDs = withAddedIDs(childIDs, len(child)-len(parent))
+	}
+
+	parentIndex := map[string]int{}
+	for i, id := range parentIDs {
+		parentIndex[id] = i
+	}
+	diff := &sliceDiff{Elements: map[string]any{}}
+	inChild := map[string]bool{}
+	for i, id := range childIDs {
+		inChild[id] = true
+		p, inParent := parentIndex[id]
+		if !inParent {
+			diff.Elements[id] = child[i]
+			continue
+		}
+		if elementDiff := a.findDiffsFromParentCommonFormat(parent[p], child[i], t.Elem(), sf); elementDiff != nil {
+			diff.Elements[id] = elementDiff
+		}
+	}
+	for _, id := range parentIDs {
+		if !inChild[id] {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	if !slices.Equal(mergedOrder(parentIDs, diff), childIDs) {
+		diff.Order = childIDs
+	}
+	if diff.empty() {
+		return nil
+	}
+	return diff.commonFormat()
+}
+
+// sliceDiffFromOverrides is the sliceDiff, in Common format, of the
+// elements of slice that the child overrides.  It is nil when the child
+// overrides none of them.
+func (a *Manager) sliceDiffFromOverrides(slice reflect.Value, context *commonFormatContext) any {
+	path := context.StackPath()
+	overridden := context.overrides.overriddenElements(path)
+	reordered := context.overrides.PathHasOverride(orderPath(path))
+	if len(overridden) == 0 && !reordered {
+		return nil
+	}
+	sliceField := context.Peek()
+	key := sliceKey(sliceField)
+	ids, ok := elementIDs(slice, key)
+	if !ok {
+		log.Printf("Field '%s' will not be saved.  The elements do not have unique keys", path)
+		return nil
+	}
+	if key == "" {
+		ids = withAddedIDs(ids, addedCount(overridden))
+	}
+
+	diff := &sliceDiff{Elements: map[string]any{}}
+	inChild := map[string]bool{}
+	for i, id := range ids {
+		inChild[id] = true
+		if !overridden[id] {
+			continue
+		}
+		context.Push(sliceElementField(sliceField, slice.Type(), id))
+		if c := a.toCommonFormatInternal(slice.Index(i).Interface(), context); c != nil {
+			diff.Elements[id] = c
+		}
+		context.Pop()
+	}
+	for id := range overridden {
+		if !inChild[id] {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	sort.Slice(diff.Removed, func(i, j int) bool { return idLess(diff.Removed[i], diff.Removed[j]) })
+	if reordered {
+		diff.Order = ids
+	}
+	if diff.empty() {
+		return nil
+	}
+	return diff.commonFormat()
+}
+
+// addedCount is how many of the overridden ids are added ids
+func addedCount(overridden map[string]bool) int {
+	count := 0
+	for id := range overridden {
+		if IsAddedElementID(id) {
+			count++
+		}
+	}
+	return count
+}
+
+// ChildSliceElementIDs is SliceElementIDs for the slice at fieldPath in
+// child.  The elements that child added to a slice identified by index
+// have added ids, such as "+0".
+func (a *Manager) ChildSliceElementIDs(child Asset, fieldPath string, sf *reflect.StructField, slice reflect.Value) []string {
+	ids := SliceElementIDs(sf, slice)
+	overrides := a.childOverridesFor(child)
+	if overrides == nil || sliceKey(sf) != "" {
+		return ids
+	}
+	return withAddedIDs(ids, addedCount(overrides.overriddenElements(fieldPath)))
+}
+
+// AppendedSliceElementID is the id of an element appended to the slice
+// at fieldPath in child, which already holds the element.  A child's
+// elements in a slice identified by index are added ids.
+func (a *Manager) AppendedSliceElementID(child Asset, fieldPath string, sf *reflect.StructField, slice reflect.Value) string {
+	if a.GetParent(child) == "" || sliceKey(sf) != "" {
+		ids := SliceElementIDs(sf, slice)
+		return ids[len(ids)-1]
+	}
+	added := 0
+	if overrides := a.childOverridesFor(child); overrides != nil {
+		added = addedCount(overrides.overriddenElements(fieldPath))
+	}
+	return addedPrefix + strconv.Itoa(added)
+}
+
+// mergeSliceDiff applies a child's diff to dest, which holds the parent's
+// elements
+func (a *Manager) mergeSliceDiff(diff *sliceDiff, dest reflect.Value, context *commonFormatContext) error {
+	t := dest.Type()
+	sliceField := context.Peek()
+	ids, ok := elementIDs(dest, sliceKey(sliceField))
+	if !ok {
+		return fmt.Errorf("cannot apply changes to the parent's elements, they do not have unique keys")
+	}
+	index := map[string]int{}
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	order := mergedOrder(ids, diff)
+	merged := reflect.MakeSlice(t, len(order), len(order))
+	for i, id := range order {
+		element := merged.Index(i)
+		if p, inParent := index[id]; inParent {
+			element.Set(dest.Index(p))
+		}
+		data, changed := diff.Elements[id]
+		if !changed {
+			continue
+		}
+		context.Push(sliceElementField(sliceField, t, id))
+		context.addError(a.unmarshalCommonFormatFromValues(reflect.ValueOf(data), element, context))
+		context.Pop()
+	}
+	dest.Set(merged)
+	return nil
+}
@@ -0,0 +1,165 @@
+package asset_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceWeapon struct {
+	Name   string
+	Damage int
+}
+
+type sliceShip struct {
+	Weapons []sliceWeapon `flat:"key:Name"`
+	Parts   []any         `flat:"inline"`
+	Tags    []string
+}
+
+func TestSliceElementOverrides(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, sliceShip{}, depLeaf{})
+
+	parent := &sliceShip{
+		Weapons: []sliceWeapon{{"laser", 1}, {"missile", 5}, {"mine", 2}},
+		Parts:   []any{&depLeaf{Image: "raw/hull.png"}},
+		Tags:    []string{"fast", "small"},
+	}
+	assert.NoError(t, m.Save("parent.json", parent))
+
+	// the child changes one weapon, removes another, adds a part and
+	// reorders what is left
+	child := &sliceShip{
+		Weapons: []sliceWeapon{{"mine", 2}, {"laser", 3}, {"bomb", 9}},
+		Parts:   []any{&depLeaf{Image: "raw/hull.png"}, &depLeaf{Image: "raw/gun.png"}},
+		Tags:    []string{"fast", "small"},
+	}
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.True(t, m.ChildOverridesField(child, "Weapons[laser].Damage"))
+	assert.True(t, m.ChildOverridesField(child, "Weapons[missile]"))
+	assert.False(t, m.ChildOverridesField(child, "Weapons[mine].Damage"))
+	assert.True(t, m.ChildOverridesField(child, "Parts[+0]"), "added elements have ids of their own")
+	assert.False(t, m.ChildOverridesField(child, "Parts[0]"))
+	assert.False(t, m.ChildOverridesField(child, "Parts"))
+	assert.NoError(t, m.Save("child.json", child))
+
+	inner := readContainer(t, wfs, "child.json")["Inner"].(js)
+	assert.Equal(t, js{
+		"Elements": js{
+			"laser": js{"Damage": float64(3)},
+			"bomb":  js{"Name": "bomb", "Damage": float64(9)},
+		},
+		"Removed": []any{"missile"},
+		"Order":   []any{"mine", "laser", "bomb"},
+	}, inner["Weapons"])
+	assert.Equal(t, []any{"+0"}, keys(inner["Parts"].(js)["Elements"].(js)))
+	assert.Nil(t, inner["Tags"], "unchanged slices are not saved")
+
+	// the parent's changes reach the child, except where the child
+	// overrides them
+	parent.Weapons = append(parent.Weapons, sliceWeapon{"cannon", 4})
+	parent.Weapons[0].Damage = 10
+	parent.Weapons[2].Damage = 20
+	parent.Tags = append(parent.Tags, "new")
+	assert.NoError(t, m.Save("parent.json", parent))
+	expected := []sliceWeapon{{"mine", 20}, {"laser", 3}, {"bomb", 9}, {"cannon", 4}}
+	assert.Equal(t, expected, child.Weapons)
+	assert.Equal(t, []string{"fast", "small", "new"}, child.Tags)
+
+	fresh := newTestManager(wfs, sliceShip{}, depLeaf{})
+	loaded, err := fresh.Load("child.json")
+	assert.NoError(t, err)
+	loadedChild := loaded.(*sliceShip)
+	assert.Equal(t, expected, loadedChild.Weapons)
+	assert.Equal(t, []any{&depLeaf{Image: "raw/hull.png"}, &depLeaf{Image: "raw/gun.png"}}, loadedChild.Parts)
+	assert.Equal(t, []string{"fast", "small", "new"}, loadedChild.Tags)
+	assert.True(t, fresh.ChildOverridesField(loaded, "Weapons[laser].Damage"))
+	assert.True(t, fresh.ChildOverridesField(loaded, "Weapons[missile]"))
+	assert.True(t, fresh.ChildOverridesField(loaded, "Parts[+0]"))
+	assert.Equal(t, []asset.Path{"parent.json", "raw/gun.png"}, fresh.Dependencies("child.json"))
+
+	// reverting an element takes it back to the parent's value
+	assert.NoError(t, m.SetChildOverrideForField(child, "Weapons[laser]", asset.OverrideDisable))
+	assert.Equal(t, []sliceWeapon{{"mine", 20}, {"laser", 10}, {"bomb", 9}, {"cannon", 4}}, child.Weapons)
+}
+
+func TestSliceAddedElements(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, sliceShip{}, depLeaf{})
+
+	hull := &depLeaf{Image: "raw/hull.png"}
+	parent := &sliceShip{Parts: []any{hull}}
+	assert.NoError(t, m.Save("parent.json", parent))
+	child := &sliceShip{Parts: []any{&depLeaf{Image: "raw/hull.png"}, &depLeaf{Image: "raw/childgun.png"}}}
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.NoError(t, m.Save("child.json", child))
+
+	// the parent appends after the child was saved, the child's element
+	// stays after the parent's instead of replacing the new one
+	parent.Parts = append(parent.Parts, &depLeaf{Image: "raw/parentengine.png"})
+	assert.NoError(t, m.Save("parent.json", parent))
+	expected := []any{
+		&depLeaf{Image: "raw/hull.png"},
+		&depLeaf{Image: "raw/parentengine.png"},
+		&depLeaf{Image: "raw/childgun.png"},
+	}
+	assert.Equal(t, expected, child.Parts)
+	loaded, err := newTestManager(wfs, sliceShip{}, depLeaf{}).Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, loaded.(*sliceShip).Parts)
+
+	// the child's elements keep their ids when it is saved again, and an
+	// element it appends is added after them
+	sf, _ := reflect.TypeOf(sliceShip{}).FieldByName("Parts")
+	parts := reflect.ValueOf(child).Elem().FieldByName("Parts")
+	assert.Equal(t, []string{"0", "1", "+0"}, m.ChildSliceElementIDs(child, "Parts", &sf, parts))
+	child.Parts = append(child.Parts, &depLeaf{Image: "raw/childshield.png"})
+	assert.Equal(t, "+1", m.AppendedSliceElementID(child, "Parts", &sf, parts))
+	assert.NoError(t, m.SetChildOverrideForField(child, "Parts[+1]", asset.OverrideEnable))
+	assert.NoError(t, m.Save("child.json", child))
+	assert.Equal(t, []any{"+0", "+1"}, sortedKeys(readContainer(t, wfs, "child.json")["Inner"].(js)["Parts"].(js)["Elements"].(js)))
+	parent.Parts = append(parent.Parts, &depLeaf{Image: "raw/parentwing.png"})
+	assert.NoError(t, m.Save("parent.json", parent))
+	assert.Equal(t, []any{
+		&depLeaf{Image: "raw/hull.png"},
+		&depLeaf{Image: "raw/parentengine.png"},
+		&depLeaf{Image: "raw/parentwing.png"},
+		&depLeaf{Image: "raw/childgun.png"},
+		&depLeaf{Image: "raw/childshield.png"},
+	}, child.Parts)
+}
+
+func TestSliceElementOverridesBinary(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, sliceShip{}, depLeaf{})
+	m.SetSaveFormat(asset.SaveFormatBinary)
+
+	parent := &sliceShip{Weapons: []sliceWeapon{{"laser", 1}}, Tags: []string{"a"}}
+	assert.NoError(t, m.Save("parent.json", parent))
+	child := &sliceShip{Weapons: []sliceWeapon{{"laser", 2}}, Tags: []string{"a", "b"}}
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.NoError(t, m.Save("child.json", child))
+
+	loaded, err := newTestManager(wfs, sliceShip{}, depLeaf{}).Load("child.json")
+	assert.NoError(t, err)
+	assert.Equal(t, child.Weapons, loaded.(*sliceShip).Weapons)
+	assert.Equal(t, child.Tags, loaded.(*sliceShip).Tags)
+}
+
+func sortedKeys(m js) []any {
+	result := keys(m)
+	sort.Slice(result, func(i, j int) bool { return result[i].(string) < result[j].(string) })
+	return result
+}
+
+func keys(m js) []any {
+	var result []any
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
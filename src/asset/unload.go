@@ -0,0 +1,308 @@
+package asset
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Unload forgets the loaded asset at path, so that it can be garbage
+// collected once nothing else refers to it.  Unloadable.Unload is called
+// on the asset and the inline values it owns.  Assets that the unloaded
+// asset refers to stay loaded, and the next Load of path reads it again.
+// Unload fails if the asset is not loaded, has unreleased Handles, or
+// another loaded asset refers to it or is its child.
+func (a *Manager) Unload(path Path) error {
+	a.mu.Lock()
+	loaded, ok := a.LoadPathToAsset[path]
+	if !ok {
+		a.mu.Unlock()
+		return fmt.Errorf("unable to unload %s, it is not loaded", path)
+	}
+	if handles := a.Handles[loaded]; handles > 0 {
+		a.mu.Unlock()
+		return fmt.Errorf("unable to unload %s, it has %d unreleased handles", path, handles)
+	}
+	if referrer := a.referrerOf(loaded); referrer != "" {
+		a.mu.Unlock()
+		return fmt.Errorf("unable to unload %s, %s refers to it", path, referrer)
+	}
+	owned, _ := walkOwned(loaded, a.AssetToLoadPath)
+	a.forget(path, loaded)
+	a.mu.Unlock()
+
+	unloadOwned(owned)
+	return nil
+}
+
+// unloadUnheld unloads the asset at path if nothing holds it, that is it
+// has no Handles and no other loaded asset refers to it.  The assets that
+// it referred to are then unloaded if nothing holds them either.
+func (a *Manager) unloadUnheld(path Path) {
+	a.mu.Lock()
+	loaded, ok := a.LoadPathToAsset[path]
+	if !ok || a.Handles[loaded] > 0 || a.referrerOf(loaded) != "" {
+		a.mu.Unlock()
+		return
+	}
+	var references []Path
+	for _, reference := range a.loadedReferences(loaded) {
+		references = append(references, a.AssetToLoadPath[reference])
+	}
+	owned, _ := walkOwned(loaded, a.AssetToLoadPath)
+	a.forget(path, loaded)
+	a.mu.Unlock()
+
+	unloadOwned(owned)
+	for _, reference := range references {
+		a.unloadUnheld(reference)
+	}
+}
+
+func unloadOwned(owned []any) {
+	for _, value := range owned {
+		if unloadable, ok := value.(Unloadable); ok {
+			unloadable.Unload()
+		}
+	}
+}
+
+// loadedReferences returns the loaded assets that root refers to, and its
+// parent.  a.mu must be held.
+func (a *Manager) loadedReferences(root Asset) []Asset {
+	w := &ownedWalker{root: root, loaded: a.AssetToLoadPath, seen: map[ownedKey]bool{}}
+	w.extra(reflect.ValueOf(root))
+	references := w.references
+	if parentPath, ok := a.ChildToParent[root]; ok {
+		if parent, ok := a.LoadPathToAsset[parentPath]; ok && !slices.Contains(references, parent) {
+			references = append(references, parent)
+		}
+	}
+	return references
+}
+
+// referrerOf returns the path of a loaded asset that refers to target, or
+// is its child, or "" if there are none.  a.mu must be held.
+func (a *Manager) referrerOf(target Asset) Path {
+	paths := maps.Keys(a.LoadPathToAsset)
+	slices.Sort(paths)
+	for _, path := range paths {
+		loaded := a.LoadPathToAsset[path]
+		if loaded != target && slices.Contains(a.loadedReferences(loaded), target) {
+			return path
+		}
+	}
+	return ""
+}
+
+// forget removes every record of the loaded asset at path.  a.mu must be
+// held.
+func (a *Manager) forget(path Path, loaded Asset) {
+	delete(a.LoadPathToAsset, path)
+	delete(a.AssetToLoadPath, loaded)
+	delete(a.ChildToParent, loaded)
+	delete(a.ChildAssetOverrides, loaded)
+	delete(a.AssetSources, loaded)
+	delete(a.Handles, loaded)
+	delete(a.AssetIDs, loaded)
+	delete(a.LoadErrors, path)
+}
+
+// Handle is a counted reference to a loaded asset.  The asset is unloaded
+// when the last Handle to it is released, unless another loaded asset
+// still refers to it, along with the assets it refers to that nothing
+// else holds.  Acquire the assets that code keeps pointers to, so that
+// they stay loaded.
+type Handle struct {
+	manager  *Manager
+	asset    Asset
+	released bool
+}
+
+// Acquire loads the asset at path, like Load, and returns a Handle to it.
+// Call Release on the Handle when the asset is no longer needed.
+func (a *Manager) Acquire(path Path) (*Handle, error) {
+	loaded, err := a.Load(path)
+	if loaded == nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	a.Handles[loaded]++
+	a.mu.Unlock()
+	return &Handle{manager: a, asset: loaded}, err
+}
+
+// Asset returns the asset that the Handle refers to
+func (h *Handle) Asset() Asset {
+	return h.asset
+}
+
+// Release gives up the Handle, unloading the asset and its unheld
+// references if this was its last Handle.  Releasing a Handle more than
+// once does nothing.
+func (h *Handle) Release() error {
+	a := h.manager
+	a.mu.Lock()
+	if h.released {
+		a.mu.Unlock()
+		return nil
+	}
+	h.released = true
+	a.Handles[h.asset]--
+	path, loaded := a.AssetToLoadPath[h.asset]
+	a.mu.Unlock()
+
+	if loaded {
+		a.unloadUnheld(path)
+	}
+	return nil
+}
+
+// AssetStats describes one loaded asset
+type AssetStats struct {
+	Path Path
+	Type string
+	// Size is the approximate number of bytes the asset holds, including
+	// its inline values and what it reports as a SizedAsset, but not the
+	// assets it refers to
+	Size    int64
+	Handles int
+}
+
+// Stats reports the loaded assets of a Manager
+type Stats struct {
+	// Assets are sorted by Path
+	Assets []AssetStats
+	// Size is the sum of the Size of the Assets
+	Size int64
+}
+
+// Stats returns the assets that are currently loaded and their
+// approximate size
+func (a *Manager) Stats() Stats {
+	a.mu.RLock()
+	loaded := make(map[Asset]Path, len(a.AssetToLoadPath))
+	handles := make(map[Asset]int, len(a.Handles))
+	for asset, path := range a.AssetToLoadPath {
+		loaded[asset] = path
+		handles[asset] = a.Handles[asset]
+	}
+	a.mu.RUnlock()
+
+	var stats Stats
+	for asset, path := range loaded {
+		owned, size := walkOwned(asset, loaded)
+		for _, value := range owned {
+			if sized, ok := value.(SizedAsset); ok {
+				size += sized.ApproximateSize()
+			}
+		}
+		_, typeName := ObjectTypeName(asset)
+		stats.Assets = append(stats.Assets, AssetStats{
+			Path:    path,
+			Type:    typeName,
+			Size:    size,
+			Handles: handles[asset],
+		})
+		stats.Size += size
+	}
+	sort.Slice(stats.Assets, func(i, j int) bool { return stats.Assets[i].Path < stats.Assets[j].Path })
+	return stats
+}
+
+// walkOwned finds the values that root owns.  These are root itself and
+// the values reached through its exported fields, stopping at other loaded
+// assets.  owned holds the pointers among them, for the Unloadable and
+// SizedAsset hooks, and size is the approximate number of bytes they use.
+func walkOwned(root Asset, loaded map[Asset]Path) (owned []any, size int64) {
+	w := &ownedWalker{root: root, loaded: loaded, seen: map[ownedKey]bool{}}
+	size = w.extra(reflect.ValueOf(root))
+	return w.owned, size
+}
+
+type ownedKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type ownedWalker struct {
+	root   Asset
+	loaded map[Asset]Path
+	seen   map[ownedKey]bool
+	owned  []any
+	// references are the other loaded assets that were reached
+	references []Asset
+}
+
+// extra returns the bytes that v refers to, not counting v itself
+func (w *ownedWalker) extra(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return 0
+		}
+		key := ownedKey{v.Pointer(), v.Type()}
+		if w.seen[key] {
+			return 0
+		}
+		w.seen[key] = true
+		if v.CanInterface() {
+			value := v.Interface()
+			if _, isAsset := w.loaded[value]; isAsset && value != w.root {
+				w.references = append(w.references, value)
+				return 0
+			}
+			w.owned = append(w.owned, value)
+		}
+		return int64(v.Type().Elem().Size()) + w.extra(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		inner := v.Elem()
+		if inner.Kind() == reflect.Pointer {
+			return w.extra(inner)
+		}
+		return int64(inner.Type().Size()) + w.extra(inner)
+	case reflect.Struct:
+		var size int64
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// unexported fields may point into other packages' internals,
+			// SizedAsset reports the memory they hold
+			if t.Field(i).IsExported() {
+				size += w.extra(v.Field(i))
+			}
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += w.extra(v.Index(i))
+		}
+		return size
+	case reflect.Slice:
+		if v.IsNil() {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += w.extra(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		t := v.Type()
+		size := int64(v.Len()) * int64(t.Key().Size()+t.Elem().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += w.extra(iter.Key()) + w.extra(iter.Value())
+		}
+		return size
+	}
+	return 0
+}
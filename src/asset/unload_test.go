@@ -0,0 +1,123 @@
+package asset_test
+
+import (
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type texture struct {
+	Path     asset.Path
+	unloaded int
+}
+
+func (t *texture) Unload()                { t.unloaded++ }
+func (t *texture) ApproximateSize() int64 { return 1000 }
+
+type sprite struct {
+	Name   string
+	Shared *texture
+	Own    *texture `flat:"inline"`
+}
+
+func TestUnload(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, texture{}, sprite{})
+	sharedTexture := &texture{Path: "raw/shared.png"}
+	assert.NoError(t, m.Save("shared.json", sharedTexture))
+	assert.NoError(t, m.Save("sprite.json", &sprite{
+		Name:   "ship",
+		Shared: sharedTexture,
+		Own:    &texture{Path: "raw/own.png"},
+	}))
+	// load into a fresh Manager, so that Shared is a reference
+	m = newTestManager(wfs, texture{}, sprite{})
+	loaded, err := m.Load("sprite.json")
+	assert.NoError(t, err)
+	s := loaded.(*sprite)
+	shared, _ := m.Load("shared.json")
+	assert.True(t, s.Shared == shared)
+
+	stats := m.Stats()
+	assert.Len(t, stats.Assets, 2)
+	assert.Equal(t, asset.Path("shared.json"), stats.Assets[0].Path)
+	assert.Equal(t, asset.Path("sprite.json"), stats.Assets[1].Path)
+	assert.Greater(t, stats.Assets[0].Size, int64(1000))
+	// the sprite owns one texture, the shared one is counted against
+	// shared.json
+	assert.Greater(t, stats.Assets[1].Size, int64(1000))
+	assert.Less(t, stats.Assets[1].Size, int64(2000))
+	assert.Equal(t, stats.Assets[0].Size+stats.Assets[1].Size, stats.Size)
+
+	assert.Error(t, m.Unload("shared.json"), "assets that loaded assets refer to cannot be unloaded")
+	assert.NotEmpty(t, m.AssetID(loaded))
+	assert.NoError(t, m.Unload("sprite.json"))
+	assert.Empty(t, m.AssetID(loaded), "unloaded assets are forgotten")
+	assert.Equal(t, 1, s.Own.unloaded, "inline values are unloaded")
+	assert.Equal(t, 0, s.Shared.unloaded, "referenced assets stay loaded")
+	assert.Error(t, m.Unload("sprite.json"))
+	assert.Len(t, m.Stats().Assets, 1)
+
+	reloaded, err := m.Load("sprite.json")
+	assert.NoError(t, err)
+	assert.True(t, reloaded != loaded, "an unloaded asset is read again")
+	assert.True(t, reloaded.(*sprite).Shared == shared)
+}
+
+func TestHandles(t *testing.T) {
+	wfs := newWriteFS()
+	assert.NoError(t, newTestManager(wfs, texture{}, sprite{}).Save("shared.json", &texture{Path: "raw/shared.png"}))
+	m := newTestManager(wfs, texture{}, sprite{})
+
+	first, err := m.Acquire("shared.json")
+	assert.NoError(t, err)
+	second, err := m.Acquire("shared.json")
+	assert.NoError(t, err)
+	tex := first.Asset().(*texture)
+	assert.True(t, second.Asset() == first.Asset())
+	assert.Equal(t, 2, m.Stats().Assets[0].Handles)
+	assert.Error(t, m.Unload("shared.json"), "held assets cannot be unloaded")
+
+	assert.NoError(t, first.Release())
+	assert.NoError(t, first.Release(), "releasing twice does nothing")
+	assert.Equal(t, 0, tex.unloaded)
+	assert.NoError(t, second.Release())
+	assert.Equal(t, 1, tex.unloaded)
+	assert.Empty(t, m.Stats().Assets)
+
+	_, err = m.Acquire("missing.json")
+	assert.Error(t, err)
+}
+
+func TestReleaseUnloadsReferences(t *testing.T) {
+	wfs := newWriteFS()
+	saver := newTestManager(wfs, texture{}, sprite{})
+	shared := &texture{Path: "raw/shared.png"}
+	assert.NoError(t, saver.Save("shared.json", shared))
+	assert.NoError(t, saver.Save("ship.json", &sprite{Name: "ship", Shared: shared, Own: &texture{}}))
+	assert.NoError(t, saver.Save("roid.json", &sprite{Name: "roid", Shared: shared, Own: &texture{}}))
+	m := newTestManager(wfs, texture{}, sprite{})
+
+	ship, err := m.Acquire("ship.json")
+	assert.NoError(t, err)
+	roid, err := m.Acquire("roid.json")
+	assert.NoError(t, err)
+	tex := ship.Asset().(*sprite).Shared
+
+	assert.NoError(t, ship.Release())
+	assert.Equal(t, 0, tex.unloaded, "the roid still refers to the texture")
+	assert.NoError(t, roid.Release())
+	assert.Equal(t, 1, tex.unloaded, "nothing holds the texture")
+	assert.Empty(t, m.Stats().Assets)
+
+	// a Handle keeps a referenced asset loaded
+	texture, err := m.Acquire("shared.json")
+	assert.NoError(t, err)
+	ship, err = m.Acquire("ship.json")
+	assert.NoError(t, err)
+	assert.NoError(t, texture.Release())
+	assert.Len(t, m.Stats().Assets, 2, "the ship still refers to the texture")
+	assert.NoError(t, ship.Release())
+	assert.Empty(t, m.Stats().Assets)
+}
@@ -0,0 +1,182 @@
+package asset
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watcher notices files that change in the Manager's file systems and
+// reloads the loaded assets that they affect.  It polls rather than
+// relying on OS notifications, so it works with any fs.FS and behaves the
+// same on every platform.
+//
+// Assets are reloaded in place, so pointers held by the game or editor see
+// the new values.  Instances made with NewInstance are copies, whoever made
+// them refreshes them from the reloaded paths (see flat.World.Reloaded).
+// Files that the Manager writes itself, such as by Save or Move, are not
+// reloaded, the loaded assets already hold what was written.  Call Poll
+// from the game loop, reloading an asset while another goroutine reads it
+// is a data race.
+type Watcher struct {
+	manager *Manager
+
+	mu     sync.Mutex
+	stamps map[Path]fileStamp
+
+	// written is the files the Manager wrote since the last Poll, it has
+	// its own lock because a reload in Poll may write files
+	writtenMu sync.Mutex
+	written   map[Path]struct{}
+}
+
+// fileStamp is what Watcher compares to decide that a file has changed
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a Watcher for the Manager's file systems.  Files
+// that exist now are the baseline, only later changes are reported.
+func (a *Manager) NewWatcher() *Watcher {
+	w := &Watcher{manager: a, written: map[Path]struct{}{}}
+	w.stamps, _ = w.scan()
+	a.mu.Lock()
+	a.watchers = append(a.watchers, w)
+	a.mu.Unlock()
+	return w
+}
+
+// wrote tells the Watchers that the Manager changed the files at paths
+// itself, so that they are not reloaded
+func (a *Manager) wrote(paths ...Path) {
+	a.mu.RLock()
+	watchers := a.watchers
+	a.mu.RUnlock()
+	for _, w := range watchers {
+		w.writtenMu.Lock()
+		for _, path := range paths {
+			w.written[path] = struct{}{}
+		}
+		w.writtenMu.Unlock()
+	}
+}
+
+func (w *Watcher) scan() (map[Path]fileStamp, error) {
+	stamps := map[Path]fileStamp{}
+	err := w.manager.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[Path(path)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps, err
+}
+
+// Poll checks every file for changes since the last Poll and reloads the
+// assets affected, returning the paths of the assets that were reloaded.
+//   - a changed asset that is loaded is reloaded, and then its children
+//   - a changed file that is not a loaded asset (such as an image) causes
+//     the loaded assets that refer to it to reload, PostLoad then reads the
+//     new file
+//
+// A reload that fails does not stop the others, the LoadErrors are joined.
+func (w *Watcher) Poll() ([]Path, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stamps, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	w.writtenMu.Lock()
+	written := w.written
+	w.written = map[Path]struct{}{}
+	w.writtenMu.Unlock()
+
+	var changed []Path
+	for path, stamp := range stamps {
+		if _, own := written[path]; own {
+			continue
+		}
+		if old, ok := w.stamps[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	removed := false
+	for path := range w.stamps {
+		if _, own := written[path]; own {
+			continue
+		}
+		if _, ok := stamps[path]; !ok {
+			removed = true
+		}
+	}
+	w.stamps = stamps
+	if len(changed) == 0 && !removed {
+		return nil, nil
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+
+	a := w.manager
+	a.InvalidateDependencies()
+
+	toReload := map[Path]struct{}{}
+	for _, path := range changed {
+		if a.isLoaded(path) {
+			toReload[path] = struct{}{}
+			continue
+		}
+		if strings.HasSuffix(string(path), ".json") {
+			// an asset nobody has loaded yet will be read fresh anyway
+			continue
+		}
+		for _, referencer := range a.Referencers(path) {
+			if a.isLoaded(referencer) {
+				toReload[referencer] = struct{}{}
+			}
+		}
+	}
+
+	return a.Reload(sortedPaths(toReload)...)
+}
+
+// Reload reloads the assets at paths that are loaded in place, then their
+// children, and returns the paths that were reloaded.  Paths that are not
+// loaded are skipped.  It lets a second Manager, such as one that a PIE
+// session plays from, follow the reloads of a Watcher on another.
+func (a *Manager) Reload(paths ...Path) ([]Path, error) {
+	var reloaded []Path
+	var errs []error
+	for _, path := range paths {
+		if !a.isLoaded(path) {
+			continue
+		}
+		reloadedAsset, err := a.LoadWithOptions(path, LoadOptions{ForceReload: true})
+		errs = append(errs, err)
+		if reloadedAsset == nil {
+			continue
+		}
+		a.reloadChildAssets(path, reloadedAsset)
+		reloaded = append(reloaded, path)
+	}
+	return reloaded, errors.Join(errs...)
+}
+
+func (a *Manager) isLoaded(path Path) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, loaded := a.LoadPathToAsset[path]
+	return loaded
+}
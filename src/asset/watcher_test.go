@@ -0,0 +1,98 @@
+package asset_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/stretchr/testify/assert"
+)
+
+type watchedImage struct {
+	Image asset.Path
+	Scale float64
+
+	assets *asset.Manager
+	pixels string
+}
+
+func (w *watchedImage) SetAssetManager(m *asset.Manager) { w.assets = m }
+
+func (w *watchedImage) PostLoad() {
+	data, _ := w.assets.ReadFile(w.Image)
+	w.pixels = string(data)
+}
+
+const watchedImageJSON = `{
+	"Type": "github.com/bradbev/flatland/src/asset_test.watchedImage",
+	"Inner": {
+		"Image": "raw/a.png",
+		"Scale": 2
+	}
+}`
+
+func TestWatcher(t *testing.T) {
+	wfs := newWriteFS()
+	m := newTestManager(wfs, watchedImage{})
+
+	wfs.WriteFile("raw/a.png", []byte("one"))
+	wfs.WriteFile("image.json", []byte(strings.Replace(watchedImageJSON, "2", "1", 1)))
+	// the child matches its parent, so it overrides nothing
+	child := &watchedImage{Image: "raw/a.png", Scale: 1}
+	parent, err := m.Load("image.json")
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetParent(child, parent))
+	assert.NoError(t, m.Save("child.json", child))
+	wfs.WriteFile("unloaded.json", []byte(watchedImageJSON))
+
+	image := parent.(*watchedImage)
+	loadedChild, err := m.Load("child.json")
+	assert.NoError(t, err)
+	child = loadedChild.(*watchedImage)
+	assert.Equal(t, "one", image.pixels)
+
+	w := m.NewWatcher()
+	reloaded, err := w.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, reloaded, "nothing has changed yet")
+
+	// an asset edited outside the editor is reloaded in place, with its children
+	wfs.WriteFile("image.json", []byte(watchedImageJSON))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"image.json"}, reloaded)
+	assert.Equal(t, 2.0, image.Scale)
+	assert.Equal(t, 2.0, child.Scale)
+
+	// a raw file reloads the loaded assets that use it, which PostLoad again
+	wfs.WriteFile("raw/a.png", []byte("two!"))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"image.json"}, reloaded)
+	assert.Equal(t, "two!", image.pixels)
+	assert.Equal(t, "two!", child.pixels)
+
+	// changes to assets that are not loaded need no work
+	wfs.WriteFile("unloaded.json", []byte(watchedImageJSON))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, reloaded)
+
+	// the Manager's own writes are not reloaded, the asset already holds them
+	image.Scale = 3
+	image.pixels = "kept"
+	assert.NoError(t, m.Save("image.json", image))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, reloaded)
+	assert.Equal(t, "kept", image.pixels)
+
+	// a bad edit is reported, and the next good edit is picked up
+	wfs.WriteFile("image.json", []byte("{ not json"))
+	_, err = w.Poll()
+	assert.Error(t, err)
+	wfs.WriteFile("image.json", []byte(watchedImageJSON))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, []asset.Path{"image.json"}, reloaded)
+}
@@ -7,14 +7,38 @@ import (
 
 type WriteableFileSystem interface {
 	WriteFile(path Path, data []byte) error
+	// Rename moves the file at oldPath to newPath, creating directories
+	// as needed.
+	Rename(oldPath, newPath Path) error
+	Remove(path Path) error
 }
 
 type writableFS struct {
 	base Path
 }
 
+func (f *writableFS) fullPath(path Path) string {
+	return filepath.Join(string(f.base), string(path))
+}
+
 func (f *writableFS) WriteFile(path Path, data []byte) error {
-	return os.WriteFile(filepath.Join(string(f.base), string(path)), data, 0777)
+	fullPath := f.fullPath(path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(fullPath, data, 0777)
+}
+
+func (f *writableFS) Rename(oldPath, newPath Path) error {
+	fullPath := f.fullPath(newPath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0777); err != nil {
+		return err
+	}
+	return os.Rename(f.fullPath(oldPath), fullPath)
+}
+
+func (f *writableFS) Remove(path Path) error {
+	return os.Remove(f.fullPath(path))
 }
 
 func NewWritableFS(basepath Path) WriteableFileSystem {
@@ -15,8 +15,18 @@ type assetEditWindow struct {
 	path        string
 	context     *TypeEditContext
 	selectModal edgui.SelectAssetModal
+
+	// overridesAction is what the overrides modal does with the selected
+	// overrides, one of the overrides modal titles
+	overridesAction   string
+	selectedOverrides map[string]bool
 }
 
+const (
+	pushOverridesTitle   = "Push To Parent"
+	revertOverridesTitle = "Revert To Parent"
+)
+
 func callEditorBeginPlay(a any) {
 	if editorPlayable, ok := a.(flat.EditorPlayable); ok {
 		editorPlayable.EditorBeginPlay()
@@ -33,8 +43,9 @@ func newAssetEditWindow(path string, target asset.Asset, context *TypeEditContex
 		target:  target,
 		context: context,
 		selectModal: edgui.SelectAssetModal{
-			Title: "Select Parent",
-			Type:  reflect.TypeOf(target),
+			Title:  "Select Parent",
+			Type:   reflect.TypeOf(target),
+			Assets: context.Ed.Assets(),
 		},
 	}
 }
@@ -47,13 +58,14 @@ func (a *assetEditWindow) Draw() error {
 		reload := false
 		edgui.WithDisabled(!enabled, func() {
 			if imgui.Button("Save") && enabled {
-				asset.Save(asset.Path(a.path), a.target)
+				a.context.Ed.Assets().Save(asset.Path(a.path), a.target)
 				a.context.hasChanged = false
 				reload = true
 			}
 			imgui.SameLine()
 			if imgui.Button("Revert") {
-				asset.LoadWithOptions(asset.Path(a.path), asset.LoadOptions{ForceReload: true})
+				_, err := a.context.Ed.Assets().LoadWithOptions(asset.Path(a.path), asset.LoadOptions{ForceReload: true})
+				a.context.Ed.RaiseError(err)
 				callEditorBeginPlay(a.target)
 			}
 		})
@@ -78,23 +90,45 @@ func (a *assetEditWindow) Draw() error {
 		if a.selectModal.DrawWithExtraHeaderUI(func() {
 			imgui.SameLine()
 			if imgui.Button("Set No Parent") {
-				asset.SetParent(a.target, nil)
+				a.context.Ed.Assets().SetParent(a.target, nil)
 				imgui.CloseCurrentPopup()
 			}
 		}) {
 			newParentPath := a.selectModal.SelectedPath()
-			currentParentPath := asset.GetParent(a.target)
+			currentParentPath := a.context.Ed.Assets().GetParent(a.target)
 			if a.path != newParentPath && newParentPath != string(currentParentPath) {
-				newParent, err := asset.Load(asset.Path(newParentPath))
-				flat.Check(err)
-				asset.SetParent(a.target, newParent)
-				a.context.SetChanged()
+				newParent, err := a.context.Ed.Assets().Load(asset.Path(newParentPath))
+				a.context.Ed.RaiseError(err)
+				if newParent != nil {
+					a.context.Ed.Assets().SetParent(a.target, newParent)
+					a.context.SetChanged()
+				}
 			}
 		}
 
-		if parent := string(asset.GetParent(a.target)); parent != "" {
+		if a.context.Ed.Assets().GetParent(a.target) != "" {
+			for _, action := range []string{pushOverridesTitle, revertOverridesTitle} {
+				imgui.SameLine()
+				if imgui.Button(action) {
+					a.openOverridesModal(action)
+				}
+			}
+			a.drawOverridesModal()
+		}
+
+		if chain := a.context.Ed.Assets().ParentChain(a.target); len(chain) > 0 {
 			imgui.SameLine()
-			imgui.Text("Parent: " + parent)
+			imgui.Text("Parent:")
+			for i, parent := range chain {
+				imgui.SameLine()
+				if i > 0 {
+					imgui.Text("<")
+					imgui.SameLine()
+				}
+				if imgui.Button(string(parent)) {
+					a.context.Ed.EditAsset(string(parent))
+				}
+			}
 		}
 		imgui.Separator()
 
@@ -108,3 +142,64 @@ func (a *assetEditWindow) Draw() error {
 	}
 	return nil
 }
+
+func (a *assetEditWindow) openOverridesModal(action string) {
+	a.overridesAction = action
+	a.selectedOverrides = map[string]bool{}
+	for _, path := range a.context.Ed.Assets().ChildOverridePaths(a.target) {
+		a.selectedOverrides[path] = true
+	}
+	imgui.OpenPopup(action)
+}
+
+// drawOverridesModal lets the user pick which overrides to push to the
+// parent or to revert
+func (a *assetEditWindow) drawOverridesModal() {
+	open := true
+	if !imgui.BeginPopupModalV(a.overridesAction, &open, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	defer imgui.EndPopup()
+
+	paths := a.context.Ed.Assets().ChildOverridePaths(a.target)
+	if len(paths) == 0 {
+		imgui.Text("No fields are overridden")
+	}
+	for _, path := range paths {
+		selected := a.selectedOverrides[path]
+		if imgui.Checkbox(path, &selected) {
+			a.selectedOverrides[path] = selected
+		}
+	}
+	if a.overridesAction == pushOverridesTitle {
+		edgui.Text("The values are saved into %s", a.context.Ed.Assets().GetParent(a.target))
+	}
+
+	var selected []string
+	for _, path := range paths {
+		if a.selectedOverrides[path] {
+			selected = append(selected, path)
+		}
+	}
+	edgui.WithDisabled(len(selected) == 0, func() {
+		if imgui.Button(a.overridesAction) && len(selected) > 0 {
+			var err error
+			if a.overridesAction == pushOverridesTitle {
+				err = a.context.Ed.Assets().ApplyOverridesToParent(a.target, selected)
+			} else {
+				err = a.context.Ed.Assets().RevertOverrides(a.target, selected)
+			}
+			a.context.Ed.RaiseError(err)
+			if err == nil {
+				// both save the asset
+				a.context.hasChanged = false
+			}
+			callEditorBeginPlay(a.target)
+			imgui.CloseCurrentPopup()
+		}
+	})
+	imgui.SameLine()
+	if imgui.Button("Cancel") {
+		imgui.CloseCurrentPopup()
+	}
+}
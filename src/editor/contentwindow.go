@@ -24,6 +24,13 @@ type contentWindow struct {
 	// used when creating an asset
 	newAssetName  string
 	assetToCreate *asset.AssetDescriptor
+
+	// used when moving or deleting an asset.  Popups opened from a
+	// context menu live in the menu's ID stack, so the modal is opened
+	// by Draw on the next frame
+	openModal   string
+	actionPath  string
+	destination string
 }
 
 func newContentWindow(editor *ImguiEditor) *contentWindow {
@@ -36,7 +43,11 @@ func newContentWindow(editor *ImguiEditor) *contentWindow {
 	return ret
 }
 
-const addModalTitle = "AddAssetModal"
+const (
+	addModalTitle    = "AddAssetModal"
+	moveModalTitle   = "Move Asset"
+	deleteModalTitle = "Delete Asset"
+)
 
 func (c *contentWindow) Draw() error {
 	defer imgui.End()
@@ -48,6 +59,12 @@ func (c *contentWindow) Draw() error {
 		c.newAssetName = "default"
 	}
 	c.drawAddAssetModal()
+	if c.openModal != "" {
+		imgui.OpenPopup(c.openModal)
+		c.openModal = ""
+	}
+	c.drawMoveModal()
+	c.drawDeleteModal()
 
 	cache := c.editor.buildFileCache()
 	avail := imgui.ContentRegionAvail()
@@ -61,7 +78,8 @@ func (c *contentWindow) Draw() error {
 
 	imgui.SameLine()
 	{ // right
-		imgui.BeginChildV("ContentChild", size, false, imgui.WindowFlagsAlwaysAutoResize)
+		imgui.BeginGroup()
+		imgui.BeginChildV("ContentChild", imgui.Vec2{X: size.X, Y: size.Y * 0.6}, false, imgui.WindowFlagsAlwaysAutoResize)
 		if imgui.BeginTable("content items", 2) {
 			for index := 0; index < len(c.ContentItems); {
 				imgui.TableNextRow()
@@ -73,21 +91,169 @@ func (c *contentWindow) Draw() error {
 						c.SelectedContentItems[text],
 						imgui.SelectableFlagsAllowDoubleClick, imgui.Vec2{}) {
 
+						c.SelectedContentItems = map[string]bool{text: true}
 						if imgui.IsMouseDoubleClicked(0) {
 							c.editor.EditAsset(filepath.Join(c.SelectedDir, text))
 						}
 					}
+					c.drawContentItemMenu(filepath.Join(c.SelectedDir, text))
 					index++
 				}
 			}
 			imgui.EndTable()
 		}
 		imgui.EndChild()
+		c.drawReferences(imgui.Vec2{X: size.X, Y: 0})
+		imgui.EndGroup()
 	}
 
 	return nil
 }
 
+// selectedPath returns the path of the selected content item, or "" if
+// nothing is selected
+func (c *contentWindow) selectedPath() string {
+	for item, selected := range c.SelectedContentItems {
+		if selected {
+			return filepath.Join(c.SelectedDir, item)
+		}
+	}
+	return ""
+}
+
+// drawReferences shows what the selected item refers to, and what refers
+// to it.  This answers "what breaks if I delete this?"
+func (c *contentWindow) drawReferences(size imgui.Vec2) {
+	imgui.BeginChildV("References", size, true, 0)
+	defer imgui.EndChild()
+
+	selected := c.selectedPath()
+	if selected == "" {
+		edgui.Text("References: select an item")
+		return
+	}
+	edgui.Text("References: %s", selected)
+	assets := c.editor.Assets()
+	c.drawReferenceList("Uses", assets.Dependencies(asset.Path(selected)))
+	c.drawReferenceList("Used By", assets.Referencers(asset.Path(selected)))
+}
+
+func (c *contentWindow) drawReferenceList(title string, paths []asset.Path) {
+	if !imgui.TreeNodeV(fmt.Sprintf("%s (%d)###%s", title, len(paths), title), imgui.TreeNodeFlagsDefaultOpen) {
+		return
+	}
+	defer imgui.TreePop()
+	for _, path := range paths {
+		if imgui.SelectableV(string(path), false, imgui.SelectableFlagsAllowDoubleClick, imgui.Vec2{}) {
+			if imgui.IsMouseDoubleClicked(0) && strings.HasSuffix(string(path), ".json") {
+				c.editor.EditAsset(string(path))
+			}
+		}
+	}
+}
+
+// drawContentItemMenu draws the right click menu for the last content item
+func (c *contentWindow) drawContentItemMenu(path string) {
+	if !imgui.BeginPopupContextItem() {
+		return
+	}
+	defer imgui.EndPopup()
+	if imgui.Selectable("Rename") {
+		c.openModal = moveModalTitle
+		c.actionPath = path
+		c.destination = filepath.Base(path)
+	}
+	if imgui.Selectable("Move") {
+		c.openModal = moveModalTitle
+		c.actionPath = path
+		c.destination = path
+	}
+	if imgui.Selectable("Delete") {
+		c.openModal = deleteModalTitle
+		c.actionPath = path
+	}
+}
+
+// drawMoveModal handles both rename and move.  A destination without a
+// directory is a rename within the current directory.
+func (c *contentWindow) drawMoveModal() {
+	open := true
+	if !imgui.BeginPopupModalV(moveModalTitle, &open, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	defer imgui.EndPopup()
+
+	edgui.Text("From: content/%s", c.actionPath)
+	edgui.InputText("To", &c.destination)
+	dest := c.destination
+	if !strings.Contains(dest, "/") {
+		dest = filepath.Join(filepath.Dir(c.actionPath), dest)
+	}
+	referencers := c.editor.Assets().Referencers(asset.Path(c.actionPath))
+	if len(referencers) > 0 {
+		edgui.Text("%d referencing assets will be updated", len(referencers))
+	}
+	if imgui.Button("Move") && dest != c.actionPath {
+		c.editor.RaiseError(c.editor.Assets().Move(asset.Path(c.actionPath), asset.Path(dest)))
+		c.refresh()
+		imgui.CloseCurrentPopup()
+	}
+	imgui.SameLine()
+	if imgui.Button("Cancel") {
+		imgui.CloseCurrentPopup()
+	}
+}
+
+// drawDeleteModal asks for confirmation, listing the assets that will be
+// left with a dangling reference
+func (c *contentWindow) drawDeleteModal() {
+	open := true
+	if !imgui.BeginPopupModalV(deleteModalTitle, &open, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	defer imgui.EndPopup()
+
+	edgui.Text("Delete content/%s?", c.actionPath)
+	referencers := c.editor.Assets().Referencers(asset.Path(c.actionPath))
+	if len(referencers) > 0 {
+		edgui.Text("These assets refer to it and will fail to load:")
+		for _, path := range referencers {
+			edgui.Text("  %s", path)
+		}
+	}
+	if imgui.Button("Delete") {
+		c.editor.RaiseError(c.editor.Assets().Delete(asset.Path(c.actionPath)))
+		c.refresh()
+		imgui.CloseCurrentPopup()
+	}
+	imgui.SameLine()
+	if imgui.Button("Cancel") {
+		imgui.CloseCurrentPopup()
+	}
+}
+
+// refresh reloads the items of the selected directory after files change
+func (c *contentWindow) refresh() {
+	var find func(node *fswalk) *fswalk
+	find = func(node *fswalk) *fswalk {
+		if node.path == c.SelectedDir {
+			return node
+		}
+		for _, n := range node.dirs {
+			if found := find(n); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	cache := c.editor.buildFileCache()
+	if node := find(cache); node != nil {
+		c.selectNode(node)
+	} else {
+		c.selectNode(cache)
+	}
+}
+
 func (c *contentWindow) selectNode(node *fswalk) {
 	c.SelectedDir = node.path
 	c.ContentItems = node.files
@@ -154,7 +320,7 @@ func (c *contentWindow) drawAddAssetModal() {
 			imgui.BeginChildV("Assets", imgui.Vec2{}, true, 0)
 			defer imgui.EndChild()
 			//edgui.Text("Assets")
-			for _, a := range asset.GetAssetDescriptors() {
+			for _, a := range c.editor.Assets().GetAssetDescriptors() {
 				imgui.TreeNodeV(a.Name, imgui.TreeNodeFlagsLeaf|imgui.TreeNodeFlagsNoTreePushOnOpen)
 				if imgui.IsItemClicked() {
 					c.assetToCreate = a
@@ -168,9 +334,5 @@ func (c *contentWindow) createNewAsset(a *asset.AssetDescriptor) {
 	obj, err := a.Create()
 	_ = err
 	dest := filepath.Join(c.SelectedDir, c.newAssetName) + ".json"
-	err = asset.Save(asset.Path(dest), obj)
-	if err != nil {
-		fmt.Printf("error %v\n", err)
-		//c.editor.raiseError(err)
-	}
+	c.editor.RaiseError(c.editor.Assets().Save(asset.Path(dest), obj))
 }
@@ -16,7 +16,7 @@ func NewEbitengineWrapper(w, h int) *EbitengineWrapper {
 
 	wrapper := &EbitengineWrapper{
 		ImguiManager: mgr,
-		Editor:       New("./content", mgr),
+		Editor:       New("./content", mgr, nil),
 	}
 
 	return wrapper
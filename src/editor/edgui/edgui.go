@@ -76,6 +76,18 @@ func WithDisabled(applyDisable bool, body func()) {
 	body()
 }
 
+// WithReadOnly shows the items drawn by body dimmed, and ignores input to
+// them, when readOnly is true
+func WithReadOnly(readOnly bool, body func()) {
+	if readOnly {
+		imgui.PushItemFlag(imgui.ItemFlagsDisabled, true)
+		defer imgui.PopItemFlag()
+		imgui.PushStyleVarFloat(imgui.StyleVarAlpha, 0.5)
+		defer imgui.PopStyleVar()
+	}
+	body()
+}
+
 func WithItemWidth(width float32, body func()) {
 	imgui.PushItemWidth(width)
 	defer imgui.PopItemWidth()
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/bradbev/flatland/src/asset"
-	"github.com/bradbev/flatland/src/flat"
 	"github.com/inkyblackness/imgui-go/v4"
 	"golang.org/x/exp/slices"
 )
@@ -24,14 +23,24 @@ func (i *selectListItem) Selected() bool {
 }
 
 type SelectAssetModal struct {
-	Title            string
-	Type             reflect.Type
+	Title string
+	Type  reflect.Type
+	// Assets is the Manager that is searched for assets of Type,
+	// when nil the asset.DefaultManager is used
+	Assets           *asset.Manager
 	open             bool
 	selectedItem     *selectListItem
 	list             FilteredList[*selectListItem]
 	wasDoubleClicked bool
 }
 
+func (s *SelectAssetModal) assets() *asset.Manager {
+	if s.Assets == nil {
+		return asset.DefaultManager()
+	}
+	return s.Assets
+}
+
 func (s *SelectAssetModal) SelectedPath() string {
 	if s.selectedItem == nil {
 		return ""
@@ -64,7 +73,7 @@ func (s *SelectAssetModal) Open() {
 	imgui.OpenPopup(s.Title)
 
 	var items []*selectListItem
-	paths, _ := asset.FilterFilesByReflectType(s.Type)
+	paths, _ := s.assets().FilterFilesByType(s.Type)
 	for _, p := range paths {
 		items = append(items, &selectListItem{
 			assetPath: p,
@@ -115,13 +124,17 @@ type SelectParentModal struct {
 	selectModal SelectAssetModal
 	target      asset.Asset
 	targetPath  asset.Path
+
+	// OnError is called with errors from loading the parent, if set
+	OnError func(err error)
 }
 
-func NewSelectParentModel(targetPath asset.Path, target asset.Asset) *SelectParentModal {
+func NewSelectParentModel(assets *asset.Manager, targetPath asset.Path, target asset.Asset) *SelectParentModal {
 	return &SelectParentModal{
 		selectModal: SelectAssetModal{
-			Title: "Select Parent",
-			Type:  reflect.TypeOf(target),
+			Title:  "Select Parent",
+			Type:   reflect.TypeOf(target),
+			Assets: assets,
 		},
 		target:     target,
 		targetPath: targetPath,
@@ -136,16 +149,21 @@ func (s *SelectParentModal) Draw() bool {
 	if s.selectModal.DrawWithExtraHeaderUI(func() {
 		imgui.SameLine()
 		if imgui.Button("Set No Parent") {
-			asset.SetParent(s.target, nil)
+			s.selectModal.assets().SetParent(s.target, nil)
 			imgui.CloseCurrentPopup()
 		}
 	}) {
 		newParentPath := s.selectModal.SelectedPath()
-		currentParentPath := asset.GetParent(s.target)
+		currentParentPath := s.selectModal.assets().GetParent(s.target)
 		if string(s.targetPath) != newParentPath && newParentPath != string(currentParentPath) {
-			newParent, err := asset.Load(asset.Path(newParentPath))
-			flat.Check(err)
-			asset.SetParent(s.target, newParent)
+			newParent, err := s.selectModal.assets().Load(asset.Path(newParentPath))
+			if err != nil && s.OnError != nil {
+				s.OnError(err)
+			}
+			if newParent == nil {
+				return false
+			}
+			s.selectModal.assets().SetParent(s.target, newParent)
 			return true
 		}
 	}
@@ -31,6 +31,9 @@ type ImguiEditor struct {
 	fsysRead  fs.FS
 	fsysWrite asset.WriteableFileSystem
 
+	// assets is the asset.Manager that all editing is done through
+	assets *asset.Manager
+
 	shouldQuit bool
 
 	menu menuManager
@@ -42,6 +45,13 @@ type ImguiEditor struct {
 
 	pie pieManager
 
+	// watcher reloads assets that are changed outside the editor
+	watcher         *asset.Watcher
+	framesUntilPoll int
+
+	// pendingErrors are shown in the error modal until dismissed
+	pendingErrors []error
+
 	// texture handling
 	nextTextureID    imgui.TextureID
 	embeddedTextures map[any]embeddedTexture
@@ -61,12 +71,19 @@ type Drawable interface {
 
 var closeDrawable = errors.New("Close")
 
-func New(path string, manager *renderer.Manager) *ImguiEditor {
-	asset.SetEditorMode()
+// New creates an editor for the content in path.  All assets are loaded and
+// saved through the assets Manager, if assets is nil then the
+// asset.DefaultManager is used.
+func New(path string, manager *renderer.Manager, assets *asset.Manager) *ImguiEditor {
+	if assets == nil {
+		assets = asset.DefaultManager()
+	}
+	assets.SetEditorMode()
 
 	ed := &ImguiEditor{
 		Manager:    manager,
 		typeEditor: newTypeEditor(),
+		assets:     assets,
 
 		fsysRead:  os.DirFS(path),
 		fsysWrite: WriteFS(path),
@@ -82,8 +99,9 @@ func New(path string, manager *renderer.Manager) *ImguiEditor {
 	ed.typeEditor.ed = ed
 	ed.pie.ed = ed
 
-	asset.RegisterFileSystem(ed.fsysRead, 0)
-	asset.RegisterWritableFileSystem(ed.fsysWrite)
+	ed.assets.RegisterFileSystem(ed.fsysRead, 0)
+	ed.assets.RegisterWritableFileSystem(ed.fsysWrite)
+	ed.watcher = ed.assets.NewWatcher()
 
 	contentWindow := newContentWindow(ed)
 	ed.AddDrawable(contentWindow)
@@ -93,12 +111,19 @@ func New(path string, manager *renderer.Manager) *ImguiEditor {
 	return ed
 }
 
+// Assets returns the asset.Manager the editor uses
+func (e *ImguiEditor) Assets() *asset.Manager {
+	return e.assets
+}
+
 func (e *ImguiEditor) AddType(typeToAdd any, edit TypeEditorFn) {
 	e.typeEditor.AddType(typeToAdd, edit)
 }
 
 func (e *ImguiEditor) Update(deltaseconds float32) error {
+	e.pollForChanges()
 	e.menu.Draw()
+	e.drawErrorModal()
 
 	// iterate Drawables, then remove any that closed
 	toRemove := map[Drawable]bool{}
@@ -119,6 +144,69 @@ func (e *ImguiEditor) Update(deltaseconds float32) error {
 	return nil
 }
 
+// RaiseError shows err to the user in a modal.  Errors raised while the
+// modal is open are added to it.  A nil err is ignored.
+func (e *ImguiEditor) RaiseError(err error) {
+	if err != nil {
+		e.pendingErrors = append(e.pendingErrors, err)
+	}
+}
+
+func (e *ImguiEditor) drawErrorModal() {
+	if len(e.pendingErrors) == 0 {
+		return
+	}
+	if !imgui.IsPopupOpen(errorModalID) {
+		imgui.OpenPopup(errorModalID)
+	}
+	if !imgui.BeginPopupModalV(errorModalID, nil, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	defer imgui.EndPopup()
+
+	for _, err := range e.pendingErrors {
+		// asset.LoadErrors are joined one per line, each with its file and field
+		for _, line := range strings.Split(err.Error(), "\n") {
+			edgui.Text("%s", line)
+		}
+	}
+	imgui.Separator()
+	if imgui.Button("OK") {
+		e.pendingErrors = nil
+		imgui.CloseCurrentPopup()
+	}
+}
+
+// hotReloadPollFrames is how often the content folder is checked for
+// changes, walking it every frame is wasteful
+const hotReloadPollFrames = 30
+
+// pollForChanges reloads assets that have been edited outside of the
+// editor.  Assets are reloaded in place, so the edit windows see the new
+// values, and running PIE games that are HotReloadable are told so that
+// they can replace the instances they made.  The editor's own saves are
+// not reloaded.
+func (e *ImguiEditor) pollForChanges() {
+	e.framesUntilPoll--
+	if e.framesUntilPoll > 0 {
+		return
+	}
+	e.framesUntilPoll = hotReloadPollFrames
+
+	reloaded, err := e.watcher.Poll()
+	e.RaiseError(err)
+	if len(reloaded) > 0 {
+		e.pie.Reloaded(reloaded)
+	}
+	for _, path := range reloaded {
+		for _, d := range e.drawables {
+			if win, ok := d.(*assetEditWindow); ok && win.path == string(path) {
+				callEditorBeginPlay(win.target)
+			}
+		}
+	}
+}
+
 func (e *ImguiEditor) StartGameCallback(startGame func() ebiten.Game) {
 	e.pie.StartGameCallback(startGame)
 }
@@ -144,9 +232,9 @@ func (e *ImguiEditor) EditAsset(path string) {
 		return
 	}
 
-	loaded, err := asset.Load(asset.Path(path))
-	if err != nil {
-		fmt.Println(err)
+	loaded, err := e.Assets().Load(asset.Path(path))
+	e.RaiseError(err)
+	if loaded == nil {
 		return
 	}
 
@@ -278,6 +366,18 @@ func (e *editorWriteFS) WriteFile(path asset.Path, data []byte) error {
 	return os.WriteFile(filepath.Join(e.base, string(path)), data, 0777)
 }
 
+func (e *editorWriteFS) Rename(oldPath, newPath asset.Path) error {
+	dest := filepath.Join(e.base, string(newPath))
+	if err := os.MkdirAll(filepath.Dir(dest), 0777); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(e.base, string(oldPath)), dest)
+}
+
+func (e *editorWriteFS) Remove(path asset.Path) error {
+	return os.Remove(filepath.Join(e.base, string(path)))
+}
+
 func WriteFS(base string) asset.WriteableFileSystem {
 	return &editorWriteFS{base: base}
 }
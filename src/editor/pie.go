@@ -4,11 +4,19 @@ import (
 	"fmt"
 	"image/color"
 
+	"github.com/bradbev/flatland/src/asset"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/inkyblackness/imgui-go/v4"
 	"golang.org/x/exp/slices"
 )
 
+// HotReloadable is implemented by PIE games that pick up the assets the
+// editor reloads while they run, flat.World.Reloaded does the work for a
+// World.
+type HotReloadable interface {
+	Reloaded(assets *asset.Manager, paths []asset.Path) error
+}
+
 type pieManager struct {
 	ed        *ImguiEditor
 	pieGames  []ebiten.Game
@@ -20,8 +28,20 @@ func (p *pieManager) StartGameCallback(startGame func() ebiten.Game) {
 }
 
 func (p *pieManager) StartGame() {
-	game := p.startGame()
-	p.pieGames = append(p.pieGames, game)
+	// a nil game failed to start, it will have raised an error
+	if game := p.startGame(); game != nil {
+		p.pieGames = append(p.pieGames, game)
+	}
+}
+
+// Reloaded tells the running PIE games that the assets at paths were
+// reloaded
+func (p *pieManager) Reloaded(paths []asset.Path) {
+	for _, game := range p.pieGames {
+		if reloadable, ok := game.(HotReloadable); ok {
+			p.ed.RaiseError(reloadable.Reloaded(p.ed.assets, paths))
+		}
+	}
 }
 
 func (p *pieManager) Update(deltaseconds float32) {
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"unsafe"
 
@@ -67,9 +69,9 @@ func (c *TypeEditContext) GetContext(key reflect.Value) {
 
 func (c *TypeEditContext) SetChanged() {
 	c.hasChanged = true
-	if asset.GetParent(c.targetAsset) != "" {
+	if c.Ed.Assets().GetParent(c.targetAsset) != "" {
 		fieldPath := c.FieldPathStackName()
-		asset.SetChildOverrideForField(c.targetAsset, fieldPath, asset.OverrideEnable)
+		c.Ed.Assets().SetChildOverrideForField(c.targetAsset, fieldPath, asset.OverrideEnable)
 	}
 }
 
@@ -104,7 +106,7 @@ func (c *TypeEditContext) FieldPathStackName() string {
 	result := ""
 	for i, sf := range c.structFieldStack {
 		result += sf.Name
-		if i < len(c.structFieldStack)-1 {
+		if i < len(c.structFieldStack)-1 && !strings.HasPrefix(c.structFieldStack[i+1].Name, "[") {
 			result += "."
 		}
 	}
@@ -260,6 +262,8 @@ func (e *typeEditor) EditValue(context *TypeEditContext, value reflect.Value) {
 			edFn = sliceAndArrayEd
 		case reflect.Slice:
 			edFn = sliceAndArrayEd
+		case reflect.Map:
+			edFn = mapEd
 
 		case reflect.Pointer:
 			edFn = interfaceAndPointerEd
@@ -377,12 +381,12 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 	onActivated := func() []string {
 		if isInline {
 			items := []string{}
-			for _, desc := range asset.FilterAssetDescriptorsByReflectType(value.Type()) {
+			for _, desc := range context.Ed.Assets().FilterAssetDescriptorsByType(value.Type()) {
 				items = append(items, desc.Name)
 			}
 			return items
 		} else {
-			items, _ := asset.FilterFilesByReflectType(value.Type())
+			items, _ := context.Ed.Assets().FilterFilesByType(value.Type())
 			return items
 		}
 	}
@@ -392,11 +396,11 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 		c.contextForInline = NewTypeEditContext(context.Ed, "", value.Interface().(asset.Asset))
 		if !value.IsNil() {
 			if isInline {
-				desc := asset.GetDescriptorForAsset(value.Interface())
+				desc := context.Ed.Assets().GetAssetDescriptor(value.Interface())
 				c.input = desc.Name
-				c.parentPath = string(asset.GetParent(value.Interface()))
+				c.parentPath = string(context.Ed.Assets().GetParent(value.Interface()))
 			} else {
-				path, _ := asset.GetLoadPathForAsset(value.Interface())
+				path, _ := context.Ed.Assets().GetLoadPathForAsset(value.Interface())
 				c.input = string(path)
 			}
 			c.lastInput = c.input
@@ -409,8 +413,11 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 			c.auto.InputText("", &c.input, onActivated)
 			if c.input != c.lastInput { // need a better check here for "input entered"
 				c.lastInput = c.input
-				loaded, err := asset.Load(asset.Path(c.input))
-				if err == nil && loaded != nil {
+				// the input is typed, so most paths fail to load until it is
+				// complete.  Only report problems with a real asset
+				loaded, err := context.Ed.Assets().Load(asset.Path(c.input))
+				if loaded != nil {
+					context.Ed.RaiseError(err)
 					value.Set(reflect.ValueOf(loaded))
 					c.contextForInline = NewTypeEditContext(context.Ed, "", loaded)
 					context.SetChanged()
@@ -424,13 +431,13 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 				c.auto.InputText("", &c.input, onActivated)
 				if c.input != c.lastInput { // need a better check here for "input entered"
 					c.lastInput = c.input
-					for _, desc := range asset.FilterAssetDescriptorsByReflectType(value.Type()) {
+					for _, desc := range context.Ed.Assets().FilterAssetDescriptorsByType(value.Type()) {
 						if desc.Name == c.input {
 							if inst, err := desc.Create(); err == nil {
 								value.Set(reflect.ValueOf(inst))
 								c.contextForInline = NewTypeEditContext(context.Ed, "", inst)
 								context.SetChanged()
-								c.parentPath = string(asset.GetParent(value.Interface()))
+								c.parentPath = string(context.Ed.Assets().GetParent(value.Interface()))
 								break
 							}
 						}
@@ -443,7 +450,7 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 				// whole width of the edit box.
 				if context.editAgainInline {
 					valueAsAsset := value.Interface().(asset.Asset)
-					parentPath := asset.GetParent(valueAsAsset)
+					parentPath := context.Ed.Assets().GetParent(valueAsAsset)
 
 					var buttonText, labelText string
 					if parentPath == "" {
@@ -457,7 +464,8 @@ func interfaceAndPointerEd(context *TypeEditContext, value reflect.Value) error
 					{ // Select parent line
 						imgui.Indent()
 						if imgui.Button(buttonText) {
-							c.selectParentModal = edgui.NewSelectParentModel("", valueAsAsset)
+							c.selectParentModal = edgui.NewSelectParentModel(context.Ed.Assets(), "", valueAsAsset)
+							c.selectParentModal.OnError = context.Ed.RaiseError
 							c.selectParentModal.Open()
 						}
 						imgui.SameLine()
@@ -500,19 +508,26 @@ func StructEd(context *TypeEditContext, value reflect.Value) error {
 	edgui.TreeNodeWithPop(treeNodeName+"##structEd", imgui.TreeNodeFlagsDefaultOpen, func() {
 		imgui.BeginTable(treeNodeName+"##table", 2)
 		for i := 0; i < t.NumField(); i++ {
-			field := value.Field(i)
 			structField := t.Field(i)
+			// saved fields are edited, unless they are hidden
+			if _, hidden := asset.GetFlatTag(&structField, "hidden"); hidden || !asset.IsSavedField(&structField) {
+				continue
+			}
+			field := asset.FieldValue(value, i)
 			func() {
 				context.PushStructField(&structField)
 				defer context.PopStructField()
-				if structField.IsExported() {
+				_, readonly := asset.GetFlatTag(&structField, "readonly")
+				edgui.WithReadOnly(readonly, func() {
 					switch structField.Type.Kind() {
 					case reflect.Array:
 						fallthrough
 					case reflect.Slice:
 						fallthrough
+					case reflect.Map:
+						fallthrough
 					case reflect.Struct:
-						// structs, arrays, slices are edited inline as
+						// structs, arrays, slices, maps are edited inline as
 						// a new TreeNode
 						// End the current table, edit the value in a new tree
 						// node and then Begin the table again
@@ -545,11 +560,11 @@ func StructEd(context *TypeEditContext, value reflect.Value) error {
 							}
 
 							// revert button
-							if asset.ChildOverridesField(context.targetAsset, context.FieldPathStackName()) {
+							if context.Ed.Assets().ChildOverridesField(context.targetAsset, context.FieldPathStackName()) {
 								imgui.SameLine()
 								if imgui.Button("¬") {
 									context.SetChanged()
-									asset.SetChildOverrideForField(context.targetAsset, context.FieldPathStackName(), asset.OverrideDisable)
+									context.Ed.Assets().SetChildOverrideForField(context.targetAsset, context.FieldPathStackName(), asset.OverrideDisable)
 								}
 								if imgui.IsItemHovered() {
 									imgui.BeginTooltip()
@@ -557,9 +572,10 @@ func StructEd(context *TypeEditContext, value reflect.Value) error {
 									imgui.EndTooltip()
 								}
 							}
+							drawFieldOrigin(context)
 						}
 					}
-				}
+				})
 			}()
 		}
 		imgui.EndTable()
@@ -568,6 +584,33 @@ func StructEd(context *TypeEditContext, value reflect.Value) error {
 	return nil
 }
 
+// drawFieldOrigin shows which asset in the parent chain supplies the value
+// of the current field, when it is not the asset being edited.  Clicking it
+// opens that asset.
+func drawFieldOrigin(context *TypeEditContext) {
+	assets := context.Ed.Assets()
+	if assets.GetParent(context.targetAsset) == "" {
+		return
+	}
+	origin := assets.ResolveFieldOrigin(context.targetAsset, context.FieldPathStackName())
+	if origin == context.targetAsset {
+		return
+	}
+	path, err := assets.GetLoadPathForAsset(origin)
+	if err != nil {
+		return
+	}
+	imgui.SameLine()
+	if imgui.Button(filepath.Base(string(path)) + "##" + context.FieldPathStackName()) {
+		context.Ed.EditAsset(string(path))
+	}
+	if imgui.IsItemHovered() {
+		imgui.BeginTooltip()
+		imgui.Text("Value from " + string(path) + ", click to open")
+		imgui.EndTooltip()
+	}
+}
+
 func getNodeName(context *TypeEditContext, value reflect.Value) string {
 	t := value.Type()
 	// select the nodeName for this slice edit
@@ -598,6 +641,33 @@ func sliceAndArrayEd(context *TypeEditContext, value reflect.Value) error {
 		logger.Fatalf("Not a slice or array - %v", t.Kind())
 	}
 
+	// slice elements are edited as if they were struct fields named by the
+	// element's id, so that a child asset overrides single elements
+	sliceField := context.StructField()
+	elementField := func(id string) *reflect.StructField {
+		sf := &reflect.StructField{Name: "[" + id + "]", Type: t.Elem()}
+		if sliceField != nil {
+			sf.Tag = sliceField.Tag
+		}
+		return sf
+	}
+	withElement := func(id string, body func()) {
+		if !isSlice {
+			// arrays are saved whole
+			body()
+			return
+		}
+		context.PushStructField(elementField(id))
+		defer context.PopStructField()
+		body()
+	}
+
+	assets := context.Ed.Assets()
+	slicePath := context.FieldPathStackName()
+	elementIDs := func() []string {
+		return assets.ChildSliceElementIDs(context.targetAsset, slicePath, sliceField, value)
+	}
+
 	treeNodeName := getNodeName(context, value)
 	sliceLen := value.Len()
 	treeNodeName = fmt.Sprintf("%s (%d)", treeNodeName, sliceLen)
@@ -611,9 +681,9 @@ func sliceAndArrayEd(context *TypeEditContext, value reflect.Value) error {
 					value.Grow(1)
 					value.SetLen(sliceLen + 1)
 					// default init the new item
-					value.Index(sliceLen).Set(reflect.New(value.Index(0).Type()).Elem())
+					value.Index(sliceLen).Set(reflect.New(t.Elem()).Elem())
 					sliceLen = value.Len()
-					context.SetChanged()
+					withElement(assets.AppendedSliceElementID(context.targetAsset, slicePath, sliceField, value), context.SetChanged)
 				}
 				imgui.SameLine()
 				if imgui.Button("Clear") {
@@ -622,19 +692,29 @@ func sliceAndArrayEd(context *TypeEditContext, value reflect.Value) error {
 					context.SetChanged()
 				}
 			}
+			ids := elementIDs()
 			toDelete := -1
 			for i := 0; i < sliceLen; i++ {
 				edgui.Text("%d", i)
 				imgui.SameLine()
 				index := value.Index(i)
-				edgui.WithID(index, func() {
-					context.EditValue(index.Addr())
-					if isSlice {
-						imgui.SameLine()
-						if imgui.Button("X") {
-							toDelete = i
+				withElement(ids[i], func() {
+					edgui.WithID(index, func() {
+						context.EditValue(index.Addr())
+						if isSlice {
+							imgui.SameLine()
+							if imgui.Button("X") {
+								toDelete = i
+							}
+							if context.Ed.Assets().ChildOverridesField(context.targetAsset, context.FieldPathStackName()) {
+								imgui.SameLine()
+								if imgui.Button("¬") {
+									context.SetChanged()
+									context.Ed.Assets().SetChildOverrideForField(context.targetAsset, context.FieldPathStackName(), asset.OverrideDisable)
+								}
+							}
 						}
-					}
+					})
 				})
 			}
 			if toDelete != -1 {
@@ -642,7 +722,174 @@ func sliceAndArrayEd(context *TypeEditContext, value reflect.Value) error {
 					value.Index(i).Set(value.Index(i + 1))
 				}
 				value.SetLen(sliceLen - 1)
-				context.SetChanged()
+				// elements identified by index shift down, so every element
+				// after the deleted one changes
+				changed := ids[toDelete:]
+				if sliceField != nil {
+					if _, keyed := asset.GetFlatTag(sliceField, "key"); keyed {
+						changed = ids[toDelete : toDelete+1]
+					}
+				}
+				if asset.IsAddedElementID(ids[toDelete]) {
+					// the child's added elements after it shift down, and
+					// the last added id is gone
+					changed = ids[toDelete : len(ids)-1]
+					withElement(ids[len(ids)-1], func() {
+						assets.SetChildOverrideForField(context.targetAsset, context.FieldPathStackName(), asset.OverrideDisable)
+					})
+				}
+				for _, id := range changed {
+					withElement(id, context.SetChanged)
+				}
+			}
+		})
+	})
+
+	return nil
+}
+
+// mapEdContext keeps an addressable copy of each map value.  Map values
+// cannot be edited in place, and editors key their context on the address
+// of the value, so the copies must live across frames.
+type mapEdContext struct {
+	values  map[string]reflect.Value
+	renames map[string]string
+	newKey  string
+}
+
+func mapEd(context *TypeEditContext, value reflect.Value) error {
+	t := value.Type()
+	if t.Kind() != reflect.Map {
+		logger.Fatalf("Not a map - %v", t.Kind())
+	}
+	c, firstTime := GetContext[mapEdContext](context, value)
+	if firstTime {
+		c.values = map[string]reflect.Value{}
+		c.renames = map[string]string{}
+	}
+
+	// each key is edited as if it were a struct field named by the key, so
+	// that a child asset overrides single keys
+	keyField := func(key string) *reflect.StructField {
+		sf := &reflect.StructField{Name: key, Type: t.Elem()}
+		if mapField := context.StructField(); mapField != nil {
+			sf.Tag = mapField.Tag
+		}
+		return sf
+	}
+	withKey := func(key string, body func()) {
+		context.PushStructField(keyField(key))
+		defer context.PopStructField()
+		body()
+	}
+
+	keys := map[string]reflect.Value{}
+	var names []string
+	iter := value.MapRange()
+	for iter.Next() {
+		name, err := asset.MapKeyString(iter.Key())
+		if err != nil {
+			edgui.Text("!!%v", err)
+			return nil
+		}
+		keys[name] = iter.Key()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	treeNodeName := fmt.Sprintf("%s (%d)", getNodeName(context, value), len(names))
+	edgui.TreeNodeWithPop(treeNodeName+"##mapEd", imgui.TreeNodeFlagsDefaultOpen, func() {
+		edgui.WithID(value, func() {
+			imgui.SameLine()
+			imgui.Text("   ")
+			imgui.SameLine()
+			edgui.WithItemWidth(100, func() {
+				imgui.InputText("##newKey", &c.newKey)
+			})
+			imgui.SameLine()
+			if imgui.Button("+") {
+				key, err := asset.ParseMapKey(c.newKey, t.Key())
+				if err != nil {
+					context.Ed.RaiseError(err)
+				} else if !value.MapIndex(key).IsValid() {
+					if value.IsNil() {
+						value.Set(reflect.MakeMap(t))
+					}
+					value.SetMapIndex(key, reflect.New(t.Elem()).Elem())
+					withKey(c.newKey, context.SetChanged)
+					c.newKey = ""
+				}
+			}
+			imgui.SameLine()
+			if imgui.Button("Clear") && len(names) > 0 {
+				value.Set(reflect.MakeMap(t))
+				// a child overrides each key it removes
+				for _, name := range names {
+					withKey(name, context.SetChanged)
+				}
+			}
+
+			toDelete := ""
+			renameFrom, renameTo := "", ""
+			for _, name := range names {
+				key := keys[name]
+				edit, ok := c.values[name]
+				if !ok {
+					edit = reflect.New(t.Elem())
+					c.values[name] = edit
+				}
+				edit.Elem().Set(value.MapIndex(key))
+
+				withKey(name, func() {
+					edgui.WithID(edit.Elem(), func() {
+						if _, ok := c.renames[name]; !ok {
+							c.renames[name] = name
+						}
+						rename := c.renames[name]
+						edgui.WithItemWidth(100, func() {
+							if imgui.InputTextV("##key", &rename, imgui.InputTextFlagsEnterReturnsTrue, nil) {
+								renameFrom, renameTo = name, rename
+							}
+						})
+						c.renames[name] = rename
+						imgui.SameLine()
+						context.EditValue(edit)
+						value.SetMapIndex(key, edit.Elem())
+						imgui.SameLine()
+						if imgui.Button("X") {
+							toDelete = name
+						}
+						if context.Ed.Assets().ChildOverridesField(context.targetAsset, context.FieldPathStackName()) {
+							imgui.SameLine()
+							if imgui.Button("¬") {
+								context.SetChanged()
+								context.Ed.Assets().SetChildOverrideForField(context.targetAsset, context.FieldPathStackName(), asset.OverrideDisable)
+							}
+						}
+					})
+				})
+			}
+
+			if toDelete != "" {
+				value.SetMapIndex(keys[toDelete], reflect.Value{})
+				delete(c.values, toDelete)
+				delete(c.renames, toDelete)
+				withKey(toDelete, context.SetChanged)
+			}
+			if renameFrom != "" && renameTo != renameFrom {
+				delete(c.renames, renameFrom)
+				newKey, err := asset.ParseMapKey(renameTo, t.Key())
+				if err != nil {
+					context.Ed.RaiseError(err)
+				} else if value.MapIndex(newKey).IsValid() {
+					context.Ed.RaiseError(fmt.Errorf("%s already has the key %s", getNodeName(context, value), renameTo))
+				} else {
+					value.SetMapIndex(newKey, value.MapIndex(keys[renameFrom]))
+					value.SetMapIndex(keys[renameFrom], reflect.Value{})
+					delete(c.values, renameFrom)
+					withKey(renameFrom, context.SetChanged)
+					withKey(renameTo, context.SetChanged)
+				}
 			}
 		})
 	})
@@ -715,14 +962,13 @@ type pathEdContext struct {
 
 func pathEd(context *TypeEditContext, value reflect.Value) error {
 	onActivated := func() []string {
-		filters := []string{}
+		var filters []string
 		if sf := context.StructField(); sf != nil {
-			val, _ := asset.GetFlatTag(sf, "filter")
-			filters = strings.Split(strings.ToLower(val), ",")
+			filters = asset.PathFilters(sf)
 		}
 
 		var items []string
-		asset.WalkFiles(func(path string, d fs.DirEntry, err error) error {
+		context.Ed.Assets().WalkFiles(func(path string, d fs.DirEntry, err error) error {
 			// do not include directories
 			if d != nil && d.IsDir() {
 				return nil
@@ -1,9 +1,5 @@
 package flat
 
-import (
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
 type Actor interface {
 	Transformer
 	Component
@@ -29,12 +25,11 @@ type Updateable interface {
 }
 
 type Drawable interface {
-	Draw(screen *ebiten.Image)
+	Draw(ctx *DrawContext)
 }
 
 // TODO - Need some other lifecycle hooks.
 // - Editor Construction?
-// - EndPlay?
 
 // Playable will be called right before a world is ready to run
 // and start ticking
@@ -42,6 +37,14 @@ type Playable interface {
 	BeginPlay()
 }
 
+// EndPlayable will be called when an actor is destroyed, or the world it
+// is in ends play.  ActorBase implements it by ending the play of its
+// components, actors that have their own EndPlay must call
+// ActorBase.EndPlay.
+type EndPlayable interface {
+	EndPlay()
+}
+
 // EditorPlayable will be called by the editor instead of Begin play
 // in certain editor situations
 type EditorPlayable interface {
@@ -49,14 +52,21 @@ type EditorPlayable interface {
 }
 
 type ComponentBase struct {
-	Transform Transform
-	owner     Component
-	Children  []Component `flat:"inline"`
+	Transform      Transform
+	Layer          Layer
+	owner          Component
+	Children       []Component `flat:"inline"`
+	transformCache transformCache
 }
 
 var _ Component = (*ComponentBase)(nil)
 
-func (c *ComponentBase) SetOwner(owner Component)        { c.owner = owner }
+func (c *ComponentBase) SetOwner(owner Component) {
+	c.owner = owner
+	// the world transform now follows the new owner
+	TransformChanged(c)
+}
+
 func (c *ComponentBase) Owner() Component                { return c.owner }
 func (c *ComponentBase) SetComponents(comps []Component) { c.Children = comps }
 func (c *ComponentBase) GetComponents() []Component      { return c.Children }
@@ -64,9 +74,13 @@ func (c *ComponentBase) GetTransform() *Transform        { return &c.Transform }
 
 type ActorBase struct {
 	Transform            Transform
+	Layer                Layer
+	LifeSpan             float64     // seconds to live once in a World, 0 is forever
 	Components           []Component `flat:"inline"`
 	updateableComponents []Updateable
 	drawableComponents   []Drawable
+	orderedDrawables     []Drawable
+	transformCache       transformCache
 }
 
 // EmptyActor can be used when you need an actor that is entirely defined
@@ -123,6 +137,24 @@ func (a *ActorBase) BeginPlay(rootParent Actor) {
 	}
 }
 
+// EndPlay ends the play of the components, in the reverse of the order
+// they began
+func (a *ActorBase) EndPlay() {
+	var ending []EndPlayable
+	for _, component := range a.Components {
+		WalkComponents(component, func(component, parent Component) {
+			if endPlayable, ok := component.(EndPlayable); ok {
+				ending = append(ending, endPlayable)
+			}
+		})
+	}
+	for i := len(ending) - 1; i >= 0; i-- {
+		ending[i].EndPlay()
+	}
+}
+
+func (a *ActorBase) lifeSpan() float64 { return a.LifeSpan }
+
 func (a *ActorBase) IsActor() {}
 
 func (a *ActorBase) GetTransform() *Transform {
@@ -135,9 +167,11 @@ func (a *ActorBase) Update() {
 	}
 }
 
-func (a *ActorBase) Draw(screen *ebiten.Image) {
-	for _, drawable := range a.drawableComponents {
-		drawable.Draw(screen)
+// Draw draws the components of the actor, lowest Layer first
+func (a *ActorBase) Draw(ctx *DrawContext) {
+	a.orderedDrawables = drawOrder(ctx, a.orderedDrawables, a.drawableComponents)
+	for _, drawable := range a.orderedDrawables {
+		drawable.Draw(ctx)
 	}
 }
 
@@ -0,0 +1,132 @@
+package flat
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Viewport is the part of the screen that a camera draws to, as fractions
+// of the screen size.  The zero Viewport is the whole screen, the left half
+// of a split screen is {Width: 0.5, Height: 1}.
+type Viewport struct {
+	X, Y          float64
+	Width, Height float64
+}
+
+// Rect returns the part of screen that the viewport covers
+func (v Viewport) Rect(screen image.Rectangle) image.Rectangle {
+	if v.Width <= 0 || v.Height <= 0 {
+		return screen
+	}
+	w, h := float64(screen.Dx()), float64(screen.Dy())
+	min := image.Pt(screen.Min.X+int(v.X*w), screen.Min.Y+int(v.Y*h))
+	max := image.Pt(screen.Min.X+int((v.X+v.Width)*w), screen.Min.Y+int((v.Y+v.Height)*h))
+	return image.Rectangle{Min: min, Max: max}.Intersect(screen)
+}
+
+// Camera is a view of the world.  The world point X, Y is drawn at the
+// centre of the camera's viewport, Zoom scales the world (a Zoom of 0 is
+// the same as 1) and Rotation, in degrees, turns the world the other way so
+// that the camera appears to turn.
+type Camera struct {
+	X, Y     float64
+	Zoom     float64
+	Rotation float64
+	Viewport Viewport
+}
+
+func (c *Camera) zoom() float64 {
+	if c.Zoom <= 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// GeoM returns the matrix that takes world coordinates to coordinates on
+// screen, which is the size of the whole screen, not the viewport
+func (c *Camera) GeoM(screen image.Rectangle) ebiten.GeoM {
+	g := ebiten.GeoM{}
+	g.Translate(-c.X, -c.Y)
+	g.Rotate(-DegToRad(c.Rotation))
+	g.Scale(c.zoom(), c.zoom())
+	r := c.Viewport.Rect(screen)
+	g.Translate(float64(r.Min.X+r.Max.X)/2, float64(r.Min.Y+r.Max.Y)/2)
+	return g
+}
+
+// WorldToScreen returns where the world point x, y is drawn on screen
+func (c *Camera) WorldToScreen(screen image.Rectangle, x, y float64) (float64, float64) {
+	g := c.GeoM(screen)
+	return g.Apply(x, y)
+}
+
+// ScreenToWorld returns the world point that is drawn at x, y on screen
+func (c *Camera) ScreenToWorld(screen image.Rectangle, x, y float64) (float64, float64) {
+	g := c.GeoM(screen)
+	g.Invert()
+	return g.Apply(x, y)
+}
+
+// CameraComponent lets an actor be a camera for the world it is in, the
+// camera follows the world position and rotation of the component.  Each
+// Active camera draws the world into its Viewport, so two cameras with
+// side by side viewports make a split screen.
+type CameraComponent struct {
+	ComponentBase
+	Zoom     float64
+	Viewport Viewport
+	Active   bool
+}
+
+func (c *CameraComponent) DefaultInitialize() {
+	c.Zoom = 1
+	c.Active = true
+}
+
+// Camera returns the view of this component in the world
+func (c *CameraComponent) Camera() Camera {
+	x, y := WorldPosition(c)
+	return Camera{
+		X:        x,
+		Y:        y,
+		Zoom:     c.Zoom,
+		Rotation: WorldRotation(c),
+		Viewport: c.Viewport,
+	}
+}
+
+// DrawContext is passed down the Draw calls of a World.  It holds the image
+// being drawn to, the camera of the viewport being drawn and the layers the
+// world hides, so that worlds
+// drawn one after another, such as a game and its editor, do not share any
+// drawing state.
+type DrawContext struct {
+	// Screen is the image to draw on, for a viewport it is the part of the
+	// screen that the viewport covers
+	Screen *ebiten.Image
+	// View takes world coordinates to screen coordinates.  Without a camera
+	// world and screen coordinates are the same.
+	View ebiten.GeoM
+
+	hiddenLayers map[Layer]bool
+}
+
+// NewDrawContext returns a context that draws to screen without a camera
+func NewDrawContext(screen *ebiten.Image) *DrawContext {
+	return &DrawContext{Screen: screen}
+}
+
+// ScreenTransform returns the matrix that takes the local coordinates of tr
+// to screen coordinates for the viewport being drawn
+func (d *DrawContext) ScreenTransform(tr Transformer) ebiten.GeoM {
+	g := WorldTransform(tr)
+	g.Concat(d.View)
+	return g
+}
+
+// LayerVisible reports whether layer is being drawn.  Drawables that draw
+// other things themselves can use it to skip hidden layers.
+func (d *DrawContext) LayerVisible(layer Layer) bool {
+	return !d.hiddenLayers[layer]
+}
@@ -0,0 +1,89 @@
+package flat
+
+import (
+	"image"
+	"testing"
+
+	"github.com/deeean/go-vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewport(t *testing.T) {
+	screen := image.Rect(0, 0, 640, 480)
+	assert.Equal(t, screen, Viewport{}.Rect(screen), "the zero viewport is the whole screen")
+	assert.Equal(t, image.Rect(320, 0, 640, 480), Viewport{X: 0.5, Width: 0.5, Height: 1}.Rect(screen))
+	assert.Equal(t, image.Rect(330, 10, 650, 490), Viewport{X: 0.5, Width: 0.5, Height: 1}.Rect(screen.Add(image.Pt(10, 10))))
+}
+
+func TestCamera(t *testing.T) {
+	screen := image.Rect(0, 0, 640, 480)
+	c := Camera{X: 320, Y: 240}
+	x, y := c.WorldToScreen(screen, 10, 20)
+	assertPoint(t, 10, 20, x, y)
+
+	c = Camera{X: 100, Y: 100, Zoom: 2, Rotation: 90}
+	x, y = c.WorldToScreen(screen, 100, 100)
+	assertPoint(t, 320, 240, x, y)
+	// the camera turns right, so the world point to its right is drawn above
+	x, y = c.WorldToScreen(screen, 110, 100)
+	assertPoint(t, 320, 220, x, y)
+	x, y = c.ScreenToWorld(screen, 320, 220)
+	assertPoint(t, 110, 100, x, y)
+}
+
+func newCameraActor(x float64, viewport Viewport) (*EmptyActor, *CameraComponent) {
+	camera := &CameraComponent{}
+	camera.Transform.DefaultInitialize()
+	camera.DefaultInitialize()
+	camera.Viewport = viewport
+	a := &EmptyActor{}
+	a.Transform.DefaultInitialize()
+	a.Transform.Location = vector3.Vector3{X: x}
+	a.Components = []Component{camera}
+	return a, camera
+}
+
+func TestWorldCameras(t *testing.T) {
+	screen := image.Rect(0, 0, 200, 100)
+	w := NewWorld()
+	w.screen = screen
+	x, y, ok := w.ScreenToWorld(5, 6)
+	assert.True(t, ok)
+	assertPoint(t, 5, 6, x, y)
+
+	left, leftCamera := newCameraActor(0, Viewport{Width: 0.5, Height: 1})
+	right, _ := newCameraActor(1000, Viewport{X: 0.5, Width: 0.5, Height: 1})
+	w.AddToWorld(left)
+	w.AddToWorld(right)
+	assert.Len(t, w.Cameras(), 2)
+
+	views := w.views(screen)
+	if assert.Len(t, views, 2) {
+		assert.Equal(t, image.Rect(0, 0, 100, 100), views[0].rect)
+		assert.Equal(t, image.Rect(100, 0, 200, 100), views[1].rect)
+	}
+	x, y, ok = w.ScreenToWorld(50, 50)
+	assert.True(t, ok)
+	assertPoint(t, 0, 0, x, y)
+	x, y, ok = w.ScreenToWorld(160, 50)
+	assert.True(t, ok)
+	assertPoint(t, 1010, 0, x, y)
+
+	// the camera follows its actor
+	left.Transform.Location.X = 30
+	TransformChanged(left)
+	x, _, _ = w.ScreenToWorld(50, 50)
+	assert.InDelta(t, 30, x, 1e-9)
+
+	leftCamera.Active = false
+	assert.Len(t, w.views(screen), 1)
+	_, _, ok = w.ScreenToWorld(50, 50)
+	assert.False(t, ok, "no viewport covers the left half")
+
+	w.RemoveFromWorld(right)
+	assert.Equal(t, []*CameraComponent{leftCamera}, w.Cameras())
+	views = w.views(screen)
+	if assert.Len(t, views, 1) {
+		assert.Equal(t, screen, views[0].rect, "without an active camera the world is drawn to the whole screen")
+	}
+}
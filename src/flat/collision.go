@@ -0,0 +1,256 @@
+package flat
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/deeean/go-vector/vector2"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/exp/slices"
+)
+
+// Colliders give a component a shape in the world.  The World finds the
+// colliders that overlap each Update and tells their actors through the
+// OverlapHandler interface.  Colliders only look for overlaps with the
+// groups they are interested in, a ship with
+//
+//	Group: "ship", Mask: ["roid"]
+//
+// overlaps colliders in the "roid" group, and the roids overlap the ship
+// without listing "ship" in their own Mask.  Colliders on the same actor
+// never overlap each other.
+type Collider interface {
+	Component
+	colliderBase() *ColliderBase
+	worldShape() shape
+}
+
+// ColliderBase has the fields shared by every collider
+type ColliderBase struct {
+	ComponentBase
+	Group     string
+	Mask      []string
+	ShowDebug bool
+}
+
+func (c *ColliderBase) colliderBase() *ColliderBase { return c }
+
+// CollidesWith reports whether c looks for overlaps with other, that is
+// when either Mask holds the other's Group
+func (c *ColliderBase) CollidesWith(other *ColliderBase) bool {
+	return slices.Contains(c.Mask, other.Group) || slices.Contains(other.Mask, c.Group)
+}
+
+// CircleCollider is a circle centred on the component.  The Radius grows
+// with the largest scale of the world transform.
+type CircleCollider struct {
+	ColliderBase
+	Radius float64
+}
+
+func (c *CircleCollider) worldShape() shape {
+	world := WorldTransform(c)
+	x, y := world.Apply(0, 0)
+	scaleX, scaleY := worldScale(&world)
+	return circleShape(x, y, c.Radius*math.Max(scaleX, scaleY))
+}
+
+func (c *CircleCollider) Draw(ctx *DrawContext) { c.drawDebug(ctx, c.worldShape()) }
+
+// BoxCollider is a Width by Height box centred on the component.  It is
+// scaled by the world transform but stays lined up with the world axes
+// when its actor turns, use a PolygonCollider for a box that turns.
+type BoxCollider struct {
+	ColliderBase
+	Width, Height float64
+}
+
+func (c *BoxCollider) worldShape() shape {
+	world := WorldTransform(c)
+	x, y := world.Apply(0, 0)
+	scaleX, scaleY := worldScale(&world)
+	halfX, halfY := c.Width*scaleX/2, c.Height*scaleY/2
+	return polygonShape(boxKind, []vector2.Vector2{
+		{X: x - halfX, Y: y - halfY},
+		{X: x + halfX, Y: y - halfY},
+		{X: x + halfX, Y: y + halfY},
+		{X: x - halfX, Y: y + halfY},
+	})
+}
+
+func (c *BoxCollider) Draw(ctx *DrawContext) { c.drawDebug(ctx, c.worldShape()) }
+
+// PolygonCollider is a convex polygon, its Points are in the coordinates
+// of the component and it turns and scales with the world transform
+type PolygonCollider struct {
+	ColliderBase
+	Points []vector2.Vector2
+}
+
+func (c *PolygonCollider) worldShape() shape {
+	world := WorldTransform(c)
+	points := make([]vector2.Vector2, len(c.Points))
+	for i, p := range c.Points {
+		points[i].X, points[i].Y = world.Apply(p.X, p.Y)
+	}
+	return polygonShape(polygonKind, points)
+}
+
+func (c *PolygonCollider) Draw(ctx *DrawContext) { c.drawDebug(ctx, c.worldShape()) }
+
+// drawDebug outlines the shape when ShowDebug is set
+func (c *ColliderBase) drawDebug(ctx *DrawContext, s shape) {
+	if !c.ShowDebug {
+		return
+	}
+	screen, view := ctx.Screen, ctx.View
+	antialias := true
+	if s.kind == circleKind {
+		x, y := view.Apply(s.x, s.y)
+		edgeX, edgeY := view.Apply(s.x+s.radius, s.y)
+		r := math.Hypot(edgeX-x, edgeY-y)
+		vector.StrokeCircle(screen, float32(x), float32(y), float32(r), 2, color.White, antialias)
+		return
+	}
+	for i, p := range s.points {
+		next := s.points[(i+1)%len(s.points)]
+		x0, y0 := view.Apply(p.X, p.Y)
+		x1, y1 := view.Apply(next.X, next.Y)
+		vector.StrokeLine(screen, float32(x0), float32(y0), float32(x1), float32(y1), 2, color.White, antialias)
+	}
+}
+
+// worldScale returns how much the world transform stretches each axis
+func worldScale(world *ebiten.GeoM) (float64, float64) {
+	return math.Hypot(world.Element(0, 0), world.Element(1, 0)),
+		math.Hypot(world.Element(0, 1), world.Element(1, 1))
+}
+
+type shapeKind int
+
+const (
+	circleKind shapeKind = iota
+	boxKind
+	polygonKind
+)
+
+// shape is a collider in world coordinates
+type shape struct {
+	kind   shapeKind
+	x, y   float64
+	radius float64
+	points []vector2.Vector2
+	bounds bounds
+}
+
+type bounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (b bounds) overlaps(other bounds) bool {
+	return b.minX < other.maxX && other.minX < b.maxX && b.minY < other.maxY && other.minY < b.maxY
+}
+
+func circleShape(x, y, radius float64) shape {
+	return shape{
+		kind:   circleKind,
+		x:      x,
+		y:      y,
+		radius: radius,
+		bounds: bounds{x - radius, y - radius, x + radius, y + radius},
+	}
+}
+
+func polygonShape(kind shapeKind, points []vector2.Vector2) shape {
+	b := bounds{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range points {
+		b.minX, b.minY = math.Min(b.minX, p.X), math.Min(b.minY, p.Y)
+		b.maxX, b.maxY = math.Max(b.maxX, p.X), math.Max(b.maxY, p.Y)
+	}
+	return shape{kind: kind, points: points, bounds: b}
+}
+
+// overlap reports whether two shapes overlap, shapes that only touch do
+// not
+func (s *shape) overlap(other *shape) bool {
+	if !s.bounds.overlaps(other.bounds) {
+		return false
+	}
+	switch {
+	case s.kind == circleKind && other.kind == circleKind:
+		dx, dy := s.x-other.x, s.y-other.y
+		r := s.radius + other.radius
+		return dx*dx+dy*dy < r*r
+	case s.kind == boxKind && other.kind == boxKind:
+		// the bounds are the boxes
+		return true
+	case s.kind == circleKind:
+		return circleOverlapsPolygon(s, other)
+	case other.kind == circleKind:
+		return circleOverlapsPolygon(other, s)
+	}
+	return polygonsOverlap(s, other)
+}
+
+// polygonsOverlap uses the separating axis test, two convex polygons do not
+// overlap when there is a line between them, and the line is parallel to
+// one of their edges
+func polygonsOverlap(a, b *shape) bool {
+	if len(a.points) < 3 || len(b.points) < 3 {
+		return false
+	}
+	for _, poly := range []*shape{a, b} {
+		for i, p := range poly.points {
+			next := poly.points[(i+1)%len(poly.points)]
+			axisX, axisY := p.Y-next.Y, next.X-p.X
+			minA, maxA := project(a.points, axisX, axisY)
+			minB, maxB := project(b.points, axisX, axisY)
+			if maxA <= minB || maxB <= minA {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// circleOverlapsPolygon is the separating axis test with the polygon's
+// edges and the line from the circle to the nearest corner
+func circleOverlapsPolygon(circle, poly *shape) bool {
+	if len(poly.points) < 3 {
+		return false
+	}
+	nearest, nearestDistance := poly.points[0], math.Inf(1)
+	for _, p := range poly.points {
+		if d := math.Hypot(p.X-circle.x, p.Y-circle.y); d < nearestDistance {
+			nearest, nearestDistance = p, d
+		}
+	}
+	axes := [][2]float64{{nearest.X - circle.x, nearest.Y - circle.y}}
+	for i, p := range poly.points {
+		next := poly.points[(i+1)%len(poly.points)]
+		axes = append(axes, [2]float64{p.Y - next.Y, next.X - p.X})
+	}
+	for _, axis := range axes {
+		length := math.Hypot(axis[0], axis[1])
+		if length == 0 {
+			continue
+		}
+		axisX, axisY := axis[0]/length, axis[1]/length
+		minP, maxP := project(poly.points, axisX, axisY)
+		centre := circle.x*axisX + circle.y*axisY
+		if maxP <= centre-circle.radius || centre+circle.radius <= minP {
+			return false
+		}
+	}
+	return true
+}
+
+func project(points []vector2.Vector2, axisX, axisY float64) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		d := p.X*axisX + p.Y*axisY
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+	return min, max
+}
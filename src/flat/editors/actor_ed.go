@@ -30,7 +30,7 @@ func actorEd(context *editor.TypeEditContext, value reflect.Value) error {
 			context: c,
 			actor:   actor,
 		}
-		c.addDialog = &AddComponentDialog{Id: "Add Component"}
+		c.addDialog = &AddComponentDialog{Id: "Add Component", Assets: context.Ed.Assets()}
 		c.valueToEdit = value
 	}
 
@@ -68,8 +68,10 @@ func renderActor(actor flat.Actor, context *editor.TypeEditContext, value reflec
 		transform := actor.GetTransform()
 		savedLocation := transform.Location
 		transform.Location = vector3.Vector3{X: f64(w) / 2, Y: f64(w) / 2, Z: 0}
-		drawable.Draw(img)
+		flat.TransformChanged(actor)
+		drawable.Draw(flat.NewDrawContext(img))
 		transform.Location = savedLocation
+		flat.TransformChanged(actor)
 	}
 	// tell imgui to draw the ebiten.Image
 	imgui.Image(id, imgui.Vec2{X: f32(w), Y: f32(w)})
@@ -248,6 +250,7 @@ func buildComponentTree(actor flat.Actor) *componentTreeNode {
 
 type AddComponentDialog struct {
 	Id            string
+	Assets        *asset.Manager
 	assetToCreate *asset.AssetDescriptor
 
 	componentDescriptors []*asset.AssetDescriptor
@@ -259,8 +262,8 @@ func (a *AddComponentDialog) Open() {
 	a.assetToCreate = nil
 
 	// cache off the component descriptors
-	actors := flat.SliceToSet(asset.FilterAssetDescriptorsByType[flat.Actor]())
-	for _, desc := range asset.FilterAssetDescriptorsByType[flat.Component]() {
+	actors := flat.SliceToSet(a.Assets.FilterAssetDescriptorsByType(reflect.TypeOf(new(flat.Actor))))
+	for _, desc := range a.Assets.FilterAssetDescriptorsByType(reflect.TypeOf(new(flat.Component))) {
 		// Don't show Actors when adding components
 		if _, isActor := actors[desc]; !isActor {
 			a.componentDescriptors = append(a.componentDescriptors, desc)
@@ -293,16 +296,19 @@ func actorBaseEd(context *editor.TypeEditContext, value reflect.Value) error {
 		"Y", &base.Transform.Location.Y,
 		"Z", &base.Transform.Location.Z) {
 		context.SetChanged()
+		flat.TransformChanged(base)
 	}
 
 	if edgui.DragFloat64("Rotation     ", &base.Transform.Rotation) {
 		context.SetChanged()
+		flat.TransformChanged(base)
 	}
 
 	if edgui.DragFloat2("Scale      ",
 		"X", &base.Transform.ScaleX,
 		"Y", &base.Transform.ScaleY) {
 		context.SetChanged()
+		flat.TransformChanged(base)
 	}
 	return nil
 }
@@ -46,6 +46,7 @@ func fontEd(context *editor.TypeEditContext, value reflect.Value) error {
 	if c.lastOptions != fnt.Options || c.lastPath != string(fnt.TtfFile) {
 		c.lastOptions = fnt.Options
 		c.lastPath = string(fnt.TtfFile)
+		fnt.SetAssetManager(context.Ed.Assets())
 		fnt.PostLoad()
 	}
 
@@ -56,6 +56,7 @@ func imageEd(context *editor.TypeEditContext, value reflect.Value) error {
 	if image.Path != c.lastImagePath {
 		c.lastImagePath = image.Path
 		img := flat.Image{Path: image.Path}
+		img.SetAssetManager(context.Ed.Assets())
 		img.PostLoad()
 		if img.GetImage() != nil {
 			*image = img
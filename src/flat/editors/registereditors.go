@@ -39,4 +39,10 @@ func RegisterAllFlatEditors(ed *editor.ImguiEditor) {
 		flat.LowerCenter:  "LowerCenter",
 		flat.LowerRight:   "LowerRight",
 	})
+
+	layers := map[any]string{}
+	for _, layer := range flat.Layers() {
+		layers[layer] = flat.LayerName(layer)
+	}
+	ed.RegisterEnum(layers)
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/bradbev/flatland/src/flat"
 )
 
+// transformEd edits a Transform without knowing what holds it, so changes
+// mark every world transform dirty
 func transformEd(context *editor.TypeEditContext, value reflect.Value) error {
 	t := value.Addr().Interface().(*flat.Transform)
 	if edgui.DragFloat3("Location   ",
@@ -15,16 +17,19 @@ func transformEd(context *editor.TypeEditContext, value reflect.Value) error {
 		"Y", &t.Location.Y,
 		"Z", &t.Location.Z) {
 		context.SetChanged()
+		flat.InvalidateTransforms()
 	}
 
 	if edgui.DragFloat64("Rotation     ", &t.Rotation) {
 		context.SetChanged()
+		flat.InvalidateTransforms()
 	}
 
 	if edgui.DragFloat2("Scale      ",
 		"X", &t.ScaleX,
 		"Y", &t.ScaleY) {
 		context.SetChanged()
+		flat.InvalidateTransforms()
 	}
 	return nil
 }
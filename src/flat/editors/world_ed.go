@@ -2,7 +2,9 @@ package editors
 
 import (
 	"fmt"
+	"image"
 	"image/color"
+	"math"
 	"reflect"
 	"strings"
 
@@ -10,7 +12,6 @@ import (
 	"github.com/bradbev/flatland/src/editor"
 	"github.com/bradbev/flatland/src/editor/edgui"
 	"github.com/bradbev/flatland/src/flat"
-	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/inkyblackness/imgui-go/v4"
 	"golang.org/x/exp/slices"
@@ -22,6 +23,8 @@ func worldEd(context *editor.TypeEditContext, value reflect.Value) error {
 	_ = c
 	if firstTime {
 		c.world = world
+		c.assets = context.Ed.Assets()
+		c.ed = context.Ed
 		c.buildWorldTree()
 		c.addDialog = &addDialog{Title: "Add Actor##uniqueID"}
 		c.addDialog.Context = c
@@ -47,28 +50,95 @@ func worldEd(context *editor.TypeEditContext, value reflect.Value) error {
 
 type worldEdContext struct {
 	world       *flat.World
+	ed          *editor.ImguiEditor
+	assets      *asset.Manager
 	root        *worldTreeNode
 	addDialog   *addDialog
 	valueToEdit reflect.Value
 	actorToEdit flat.Actor
+
+	// camera is the editor's view of the world, it pans and zooms with
+	// the mouse
+	camera       flat.Camera
+	cameraPlaced bool
 }
 
 func (w *worldEdContext) renderWorld(context *editor.TypeEditContext, value reflect.Value) {
 	width := imgui.ColumnWidth()
 	id, img := context.Ed.GetImguiTexture(value, width, width)
+	screen := img.Bounds()
+	if !w.cameraPlaced {
+		w.resetCamera(screen)
+	}
 	img.Fill(color.Black)
-	w.world.Draw(img)
+	w.world.DrawWithCamera(img, &w.camera)
 	for i, a := range w.world.PersistentActors {
 		if a == nil {
 			continue
 		}
-		g := ebiten.GeoM{}
-		flat.ApplyTransform(a.GetTransform(), &g)
-		x, y := g.Apply(0, 0)
+		x, y := flat.WorldPosition(a)
+		x, y = w.camera.WorldToScreen(screen, x, y)
 		s := fmt.Sprintf("%v", i)
 		ebitenutil.DebugPrintAt(img, s, int(x), int(y))
 	}
 	imgui.Image(id, imgui.Vec2{X: f32(width), Y: f32(width)})
+	if imgui.IsItemHovered() {
+		w.panAndZoom(screen, imgui.ItemRectMin())
+	}
+	if imgui.Button("Reset View") {
+		w.resetCamera(screen)
+	}
+	imgui.SameLine()
+	imgui.Text(fmt.Sprintf("Zoom %.0f%%, drag with the right mouse button to pan", w.camera.Zoom*100))
+	w.renderLayerToggles()
+}
+
+// renderLayerToggles shows or hides each named layer in the view
+func (w *worldEdContext) renderLayerToggles() {
+	imgui.Text("Layers")
+	for _, layer := range flat.Layers() {
+		imgui.SameLine()
+		visible := w.world.IsLayerVisible(layer)
+		if imgui.Checkbox(flat.LayerName(layer)+"##layer", &visible) {
+			w.world.SetLayerVisible(layer, visible)
+		}
+	}
+}
+
+// resetCamera shows the world without panning or zooming, so that world
+// and screen coordinates are the same
+func (w *worldEdContext) resetCamera(screen image.Rectangle) {
+	w.camera = flat.Camera{
+		X:    float64(screen.Min.X+screen.Max.X) / 2,
+		Y:    float64(screen.Min.Y+screen.Max.Y) / 2,
+		Zoom: 1,
+	}
+	w.cameraPlaced = true
+}
+
+// panAndZoom moves the camera while the mouse is over the world image at
+// origin.  The wheel zooms about the mouse and the right button drags.
+func (w *worldEdContext) panAndZoom(screen image.Rectangle, origin imgui.Vec2) {
+	io := imgui.CurrentIO()
+	mouse := io.MousePosition()
+	x, y := float64(mouse.X-origin.X), float64(mouse.Y-origin.Y)
+
+	if imgui.IsMouseDown(1) {
+		delta := io.MouseDelta()
+		fromX, fromY := w.camera.ScreenToWorld(screen, x-float64(delta.X), y-float64(delta.Y))
+		toX, toY := w.camera.ScreenToWorld(screen, x, y)
+		w.camera.X += fromX - toX
+		w.camera.Y += fromY - toY
+	}
+
+	if _, wheel := io.MouseWheel(); wheel != 0 {
+		beforeX, beforeY := w.camera.ScreenToWorld(screen, x, y)
+		zoom := w.camera.Zoom * math.Pow(1.1, float64(wheel))
+		w.camera.Zoom = flat.Clamp(zoom, 0.05, 20)
+		afterX, afterY := w.camera.ScreenToWorld(screen, x, y)
+		w.camera.X += beforeX - afterX
+		w.camera.Y += beforeY - afterY
+	}
 }
 
 type worldTreeHandler struct {
@@ -142,11 +212,11 @@ func (w *worldEdContext) buildWorldTree() {
 	}
 	for _, actor := range w.world.PersistentActors {
 		nodeName := ""
-		if name, _ := asset.GetLoadPathForAsset(actor); name != "" {
+		if name, _ := w.assets.GetLoadPathForAsset(actor); name != "" {
 			nodeName = string(name)
 		}
 		if nodeName == "" {
-			if parentPath := asset.GetParent(actor); parentPath != "" {
+			if parentPath := w.assets.GetParent(actor); parentPath != "" {
 				nodeName = string(parentPath)
 			}
 		}
@@ -225,7 +295,7 @@ func (a *addDialog) Open() {
 	imgui.OpenPopup(a.Title)
 
 	var items []*addAssetItem
-	paths, _ := asset.FilterFilesByReflectType(reflect.TypeOf(new(flat.Actor)))
+	paths, _ := a.Context.assets.FilterFilesByType(reflect.TypeOf(new(flat.Actor)))
 	for _, p := range paths {
 		items = append(items, &addAssetItem{
 			assetPath: p,
@@ -245,11 +315,17 @@ func (a *addDialog) Draw() bool {
 			if imgui.Button("Add") || a.wasDoubleClicked {
 				var actorToAdd flat.Actor
 				// an existing actor was selected.  Load it and set it as the parent
-				parent, err := asset.Load(asset.Path(a.selectedItem.assetPath))
-				flat.Check(err)
-				instance, err := asset.NewInstance(parent)
-				flat.Check(err)
-				asset.SetParent(instance, parent)
+				parent, err := a.Context.assets.Load(asset.Path(a.selectedItem.assetPath))
+				a.Context.ed.RaiseError(err)
+				if parent == nil {
+					return
+				}
+				instance, err := a.Context.assets.NewInstance(parent)
+				if err != nil {
+					a.Context.ed.RaiseError(err)
+					return
+				}
+				a.Context.assets.SetParent(instance, parent)
 				actorToAdd = instance.(flat.Actor)
 
 				// Add the new actor to the world
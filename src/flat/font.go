@@ -11,7 +11,12 @@ type Font struct {
 	TtfFile asset.Path `flat:"filter:ttf"`
 	Options opentype.FaceOptions
 
-	face font.Face
+	face   font.Face
+	assets *asset.Manager
+}
+
+func (f *Font) SetAssetManager(assets *asset.Manager) {
+	f.assets = assets
 }
 
 func (f *Font) Face() font.Face {
@@ -25,7 +30,7 @@ func (f *Font) DefaultInitialize() {
 }
 
 func (f *Font) PostLoad() {
-	d, err := asset.ReadFile(f.TtfFile)
+	d, err := managerOrDefault(f.assets).ReadFile(f.TtfFile)
 	if err != nil {
 		return
 	}
@@ -39,3 +44,11 @@ func (f *Font) PostLoad() {
 	}
 	f.face = face
 }
+
+// Unload closes the font face, it is called when the asset is unloaded
+func (f *Font) Unload() {
+	if f.face != nil {
+		f.face.Close()
+		f.face = nil
+	}
+}
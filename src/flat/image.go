@@ -6,6 +6,7 @@ import (
 	"image"
 	_ "image/png"
 	"log"
+	"math"
 
 	"github.com/bradbev/flatland/src/asset"
 
@@ -14,13 +15,18 @@ import (
 )
 
 type Image struct {
-	Path asset.Path `filter:"png"`
-	img  *ebiten.Image
+	Path   asset.Path `filter:"png"`
+	img    *ebiten.Image
+	assets *asset.Manager
+}
+
+func (i *Image) SetAssetManager(assets *asset.Manager) {
+	i.assets = assets
 }
 
 func (i *Image) PostLoad() {
 	fmt.Printf("Post load for Image %#v\n", i)
-	content, err := asset.ReadFile(i.Path)
+	content, err := managerOrDefault(i.assets).ReadFile(i.Path)
 	if err != nil {
 		log.Print(err)
 		return
@@ -35,6 +41,23 @@ func (i *Image) PostLoad() {
 	fmt.Printf("[Done] Post load for Image %#v\n", i)
 }
 
+// Unload disposes of the texture, it is called when the asset is unloaded
+func (i *Image) Unload() {
+	if i.img != nil {
+		i.img.Dispose()
+		i.img = nil
+	}
+}
+
+// ApproximateSize is the size of the texture in bytes
+func (i *Image) ApproximateSize() int64 {
+	if i.img == nil {
+		return 0
+	}
+	bounds := i.img.Bounds()
+	return int64(bounds.Dx()) * int64(bounds.Dy()) * 4
+}
+
 func (i *Image) GetImage() *ebiten.Image {
 	return i.img
 }
@@ -77,20 +100,26 @@ func (c *ImageComponent) BeginPlay() {
 	c.op.GeoM.Translate(-c.dimensions.X/2.0, -c.dimensions.Y/2.0)
 }
 
-func (c *ImageComponent) Draw(screen *ebiten.Image) {
+func (c *ImageComponent) Draw(ctx *DrawContext) {
 	op := c.op
-	ApplyComponentTransforms(c, &op.GeoM)
+	op.GeoM.Concat(ctx.ScreenTransform(c))
 
 	if c.Image != nil && c.Image.GetImage() != nil {
-		screen.DrawImage(c.Image.GetImage(), &op)
+		ctx.Screen.DrawImage(c.Image.GetImage(), &op)
 	}
 }
 
+// Bounds is the world space rectangle that the image covers, it grows to
+// hold the corners of a rotated or scaled image
 func (c *ImageComponent) Bounds() image.Rectangle {
-	g := c.op.GeoM
-	ApplyComponentTransforms(c, &g)
-	minX, minY := g.Apply(-c.dimensions.X/2.0, -c.dimensions.Y/2.0)
-	maxX, maxY := minX+c.dimensions.X, minY+c.dimensions.Y
-
-	return image.Rect(int(minX), int(minY), int(maxX), int(maxY))
+	world := WorldTransform(c)
+	halfX, halfY := c.dimensions.X/2.0, c.dimensions.Y/2.0
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{-halfX, -halfY}, {halfX, -halfY}, {-halfX, halfY}, {halfX, halfY}} {
+		x, y := world.Apply(corner[0], corner[1])
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
 }
@@ -0,0 +1,87 @@
+package flat
+
+import (
+	"sort"
+
+	"golang.org/x/exp/slices"
+)
+
+// Layer orders drawing.  Lower layers are drawn first, so higher layers
+// are on top, and drawables in the same layer are drawn in the order they
+// were added.  The World orders its actors by layer, and an actor orders
+// its components by layer.
+type Layer int32
+
+const (
+	LayerBackground Layer = -100
+	LayerDefault    Layer = 0
+	LayerForeground Layer = 100
+	LayerUI         Layer = 200
+)
+
+var layerNames = map[Layer]string{
+	LayerBackground: "Background",
+	LayerDefault:    "Default",
+	LayerForeground: "Foreground",
+	LayerUI:         "UI",
+}
+
+// RegisterLayer names a layer so that it can be chosen in the editor.
+// Register layers before the editors are registered.
+func RegisterLayer(layer Layer, name string) {
+	layerNames[layer] = name
+}
+
+// LayerName returns the registered name of layer, or "" if it has none
+func LayerName(layer Layer) string {
+	return layerNames[layer]
+}
+
+// Layers returns the named layers, lowest first
+func Layers() []Layer {
+	layers := make([]Layer, 0, len(layerNames))
+	for layer := range layerNames {
+		layers = append(layers, layer)
+	}
+	sort.Slice(layers, func(i, j int) bool { return layers[i] < layers[j] })
+	return layers
+}
+
+// Layered is implemented by drawables that have a draw Layer, drawables
+// without one are drawn in LayerDefault.  ComponentBase and ActorBase
+// implement it with their Layer field.
+type Layered interface {
+	DrawLayer() Layer
+}
+
+func (c *ComponentBase) DrawLayer() Layer { return c.Layer }
+func (a *ActorBase) DrawLayer() Layer     { return a.Layer }
+
+func layerOf(d any) Layer {
+	if layered, ok := d.(Layered); ok {
+		return layered.DrawLayer()
+	}
+	return LayerDefault
+}
+
+// drawOrder fills order with the drawables that ctx draws, sorted by layer.
+// The sort is stable, so the drawables in a layer keep their order.
+func drawOrder[T any](ctx *DrawContext, order []T, drawables []T) []T {
+	order = order[:0]
+	for _, d := range drawables {
+		if ctx.LayerVisible(layerOf(d)) {
+			order = append(order, d)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b T) int {
+		la, lb := layerOf(a), layerOf(b)
+		if la < lb {
+			return -1
+		}
+		if la > lb {
+			return 1
+		}
+		return 0
+	})
+	return order
+}
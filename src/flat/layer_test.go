@@ -0,0 +1,93 @@
+package flat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type drawLog struct {
+	drawn []string
+}
+
+type layerTestComponent struct {
+	ComponentBase
+	name string
+	log  *drawLog
+}
+
+func (c *layerTestComponent) Draw(ctx *DrawContext) {
+	c.log.drawn = append(c.log.drawn, c.name)
+}
+
+type layerTestActor struct {
+	ActorBase
+	name string
+}
+
+func (a *layerTestActor) BeginPlay() { a.ActorBase.BeginPlay(a) }
+
+func TestActorDrawsComponentsByLayer(t *testing.T) {
+	log := &drawLog{}
+	component := func(name string, layer Layer) Component {
+		c := &layerTestComponent{name: name, log: log}
+		c.Layer = layer
+		return c
+	}
+	a := &layerTestActor{}
+	a.Components = []Component{
+		component("text", LayerUI),
+		component("ship", LayerDefault),
+		component("stars", LayerBackground),
+		component("flame", LayerDefault),
+	}
+	a.BeginPlay()
+	a.Draw(NewDrawContext(nil))
+	assert.Equal(t, []string{"stars", "ship", "flame", "text"}, log.drawn)
+
+	// layers can change while playing
+	log.drawn = nil
+	a.Components[2].(*layerTestComponent).Layer = LayerForeground
+	a.Draw(NewDrawContext(nil))
+	assert.Equal(t, []string{"ship", "flame", "stars", "text"}, log.drawn)
+
+	log.drawn = nil
+	a.Draw(&DrawContext{hiddenLayers: map[Layer]bool{LayerDefault: true}})
+	assert.Equal(t, []string{"stars", "text"}, log.drawn)
+}
+
+func TestWorldDrawOrder(t *testing.T) {
+	actor := func(name string, layer Layer) *layerTestActor {
+		a := &layerTestActor{name: name}
+		a.Layer = layer
+		return a
+	}
+	w := NewWorld()
+	w.AddToWorld(actor("hud", LayerUI))
+	w.AddToWorld(actor("ship", LayerDefault))
+	w.AddToWorld(actor("background", LayerBackground))
+	w.AddToWorld(actor("roid", LayerDefault))
+
+	names := func(ctx *DrawContext) []string {
+		var result []string
+		for _, d := range drawOrder(ctx, nil, w.drawables) {
+			result = append(result, d.(*layerTestActor).name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"background", "ship", "roid", "hud"}, names(NewDrawContext(nil)))
+
+	w.SetLayerVisible(LayerUI, false)
+	assert.False(t, w.IsLayerVisible(LayerUI))
+	assert.Equal(t, []string{"background", "ship", "roid"}, names(&DrawContext{hiddenLayers: w.hiddenLayers}))
+	w.SetLayerVisible(LayerUI, true)
+	assert.True(t, w.IsLayerVisible(LayerUI))
+}
+
+func TestRegisterLayer(t *testing.T) {
+	const layerParticles Layer = 50
+	RegisterLayer(layerParticles, "Particles")
+	defer delete(layerNames, layerParticles)
+	assert.Equal(t, "Particles", LayerName(layerParticles))
+	assert.Equal(t, []Layer{LayerBackground, LayerDefault, layerParticles, LayerForeground, LayerUI}, Layers())
+}
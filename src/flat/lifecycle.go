@@ -0,0 +1,202 @@
+package flat
+
+import (
+	"errors"
+
+	"github.com/bradbev/flatland/src/asset"
+	"golang.org/x/exp/slices"
+)
+
+// An actor's life in a World is
+//
+//	AddToWorld or Spawn -> BeginPlay -> Update and Draw each tick -> Destroy -> EndPlay
+//
+// Actors are spawned and destroyed while other actors update, a ship fires
+// a bullet and the bullet destroys a roid.  So that the actors being
+// updated do not change under the World, actors that are added or removed
+// while the World updates or draws are queued, and are added or removed in
+// order when the tick is done.  Actors that are queued to be removed stop
+// colliding straight away.
+
+type pendingChange struct {
+	actor   Actor
+	add     bool
+	endPlay bool
+}
+
+// Reinstanceable is implemented by actors that keep state that is not saved,
+// such as a velocity, when World.Reloaded replaces them.  It is called on
+// the new instance, after its BeginPlay, with the actor it replaces.
+type Reinstanceable interface {
+	Reinstanced(old Actor)
+}
+
+// lifeSpanned is implemented by ActorBase for its LifeSpan field
+type lifeSpanned interface {
+	lifeSpan() float64
+}
+
+// Destroy removes actor from the world and ends its play.  While the world
+// updates or draws the actor is destroyed when the tick is done.
+func (w *World) Destroy(actor Actor) {
+	if w.ticking > 0 {
+		w.queueRemove(actor, true)
+		return
+	}
+	w.destroyNow(actor)
+}
+
+func (w *World) destroyNow(actor Actor) {
+	if !w.removeNow(actor) {
+		return
+	}
+	if endPlayable, ok := actor.(EndPlayable); ok {
+		endPlayable.EndPlay()
+	}
+}
+
+func (w *World) queueRemove(actor Actor, endPlay bool) {
+	for i := range w.pending {
+		if change := &w.pending[i]; change.actor == actor && !change.add {
+			change.endPlay = change.endPlay || endPlay
+			return
+		}
+	}
+	w.pending = append(w.pending, pendingChange{actor: actor, endPlay: endPlay})
+	w.Physics().remove(actor)
+}
+
+// Spawn adds a new instance of template to the world at transform, and
+// returns the instance.  template is an actor asset, such as a *Bullet
+// loaded from bullet.json.
+func (w *World) Spawn(template Actor, transform Transform) (Actor, error) {
+	instance, err := w.AssetManager().NewInstance(template)
+	if err != nil {
+		return nil, err
+	}
+	actor, ok := instance.(Actor)
+	if !ok {
+		return nil, Errorf("%T is not an Actor", instance)
+	}
+	SetTransform(actor, transform)
+	w.setTemplate(actor, template)
+	w.AddToWorld(actor)
+	return actor, nil
+}
+
+func (w *World) setTemplate(actor, template Actor) {
+	if w.templates == nil {
+		w.templates = map[Actor]Actor{}
+	}
+	w.templates[actor] = template
+}
+
+// Reloaded refreshes a playing world after the assets at paths were
+// reloaded in assets, such as by the editor's asset.Watcher while a PIE
+// session runs.  When assets is not the world's Manager the paths are
+// reloaded in the world's Manager first.  A world whose own file was
+// reloaded begins its play again.  Otherwise the actors made from a
+// reloaded asset, or from a child of one, are destroyed and spawned again
+// from it at the same transform, see Reinstanceable.
+func (w *World) Reloaded(assets *asset.Manager, paths []asset.Path) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	var err error
+	manager := w.AssetManager()
+	if manager != managerOrDefault(assets) {
+		paths, err = manager.Reload(paths...)
+	}
+	reloaded := map[asset.Path]bool{}
+	for _, path := range paths {
+		reloaded[path] = true
+	}
+
+	if path, _ := manager.GetLoadPathForAsset(w); reloaded[path] {
+		w.EndPlay()
+		w.BeginPlay()
+		return err
+	}
+	var stale []Actor
+	for _, actor := range w.actors {
+		template, ok := w.templates[actor]
+		if !ok {
+			continue
+		}
+		path, _ := manager.GetLoadPathForAsset(template)
+		if reloaded[path] || slices.ContainsFunc(manager.ParentChain(template), func(p asset.Path) bool {
+			return reloaded[p]
+		}) {
+			stale = append(stale, actor)
+		}
+	}
+	for _, actor := range stale {
+		template := w.templates[actor]
+		transform := *actor.GetTransform()
+		w.Destroy(actor)
+		instance, spawnErr := w.Spawn(template, transform)
+		if spawnErr != nil {
+			err = errors.Join(err, spawnErr)
+			continue
+		}
+		if reinstanced, ok := instance.(Reinstanceable); ok {
+			reinstanced.Reinstanced(actor)
+		}
+	}
+	return err
+}
+
+// DestroyAfter destroys actor when seconds of updates have passed, it
+// replaces the actor's LifeSpan
+func (w *World) DestroyAfter(actor Actor, seconds float64) {
+	if w.lifeSpans == nil {
+		w.lifeSpans = map[Actor]float64{}
+	}
+	w.lifeSpans[actor] = seconds
+}
+
+// updateLifeSpans destroys the actors whose life span is over, in the
+// order they were added
+func (w *World) updateLifeSpans(deltaseconds float64) {
+	if len(w.lifeSpans) == 0 {
+		return
+	}
+	var expired []Actor
+	for _, actor := range w.actors {
+		remaining, ok := w.lifeSpans[actor]
+		if !ok {
+			continue
+		}
+		remaining -= deltaseconds
+		w.lifeSpans[actor] = remaining
+		if remaining <= 0 {
+			expired = append(expired, actor)
+		}
+	}
+	for _, actor := range expired {
+		delete(w.lifeSpans, actor)
+		w.Destroy(actor)
+	}
+}
+
+// endTick adds and removes the queued actors once the world is done with
+// updating or drawing.  Actors that are spawned or destroyed by BeginPlay
+// and EndPlay are added and removed straight away.
+func (w *World) endTick() {
+	w.ticking--
+	if w.ticking > 0 {
+		return
+	}
+	for len(w.pending) > 0 {
+		change := w.pending[0]
+		w.pending = slices.Delete(w.pending, 0, 1)
+		switch {
+		case change.add:
+			w.addNow(change.actor)
+		case change.endPlay:
+			w.destroyNow(change.actor)
+		default:
+			w.removeNow(change.actor)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package flat_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/bradbev/flatland/src/asset"
+	"github.com/bradbev/flatland/src/flat"
+	"github.com/deeean/go-vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+var lifecycleLog []string
+
+type lifeComponent struct {
+	flat.ComponentBase
+	Name string
+}
+
+func (c *lifeComponent) BeginPlay() { lifecycleLog = append(lifecycleLog, c.Name+" begin") }
+func (c *lifeComponent) EndPlay()   { lifecycleLog = append(lifecycleLog, c.Name+" end") }
+
+type lifeActor struct {
+	flat.ActorBase
+	Name     string
+	onUpdate func()
+}
+
+func (a *lifeActor) BeginPlay() {
+	lifecycleLog = append(lifecycleLog, a.Name+" begin")
+	a.ActorBase.BeginPlay(a)
+}
+
+func (a *lifeActor) Update() {
+	lifecycleLog = append(lifecycleLog, a.Name+" update")
+	if a.onUpdate != nil {
+		a.onUpdate()
+	}
+}
+
+func (a *lifeActor) EndPlay() {
+	lifecycleLog = append(lifecycleLog, a.Name+" end")
+	a.ActorBase.EndPlay()
+}
+
+func newLifeActor(name string, components ...string) *lifeActor {
+	a := &lifeActor{Name: name}
+	a.Transform.DefaultInitialize()
+	for _, c := range components {
+		a.Components = append(a.Components, &lifeComponent{Name: c})
+	}
+	return a
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	lifecycleLog = nil
+	w := flat.NewWorld()
+	ship := newLifeActor("ship", "ship/gun", "ship/engine")
+	roid := newLifeActor("roid")
+	w.AddToWorld(ship)
+	w.AddToWorld(roid)
+	assert.Equal(t, []string{"ship begin", "ship/gun begin", "ship/engine begin", "roid begin"}, lifecycleLog,
+		"actors added outside of a tick begin play straight away")
+
+	lifecycleLog = nil
+	bullet := newLifeActor("bullet")
+	ship.onUpdate = func() {
+		w.AddToWorld(bullet)
+		w.Destroy(ship)
+		w.Destroy(ship)
+	}
+	w.Update()
+	assert.Equal(t, []string{
+		"ship update",
+		"roid update",
+		"bullet begin",
+		"ship end",
+		"ship/engine end",
+		"ship/gun end",
+	}, lifecycleLog, "changes made during the update wait until it is done, and happen in order")
+	assert.Equal(t, []*lifeActor{roid, bullet}, flat.FindActorsByType[*lifeActor](w))
+
+	lifecycleLog = nil
+	w.Update()
+	assert.Equal(t, []string{"roid update", "bullet update"}, lifecycleLog)
+}
+
+func TestDestroyOutsideTick(t *testing.T) {
+	lifecycleLog = nil
+	w := flat.NewWorld()
+	roid := newLifeActor("roid", "roid/image")
+	w.AddToWorld(roid)
+	lifecycleLog = nil
+
+	w.Destroy(roid)
+	w.Destroy(roid)
+	assert.Equal(t, []string{"roid end", "roid/image end"}, lifecycleLog, "play ends once")
+	assert.Empty(t, flat.FindActorsByType[*lifeActor](w))
+
+	// removing keeps the actor playing, so that it can be added again
+	lifecycleLog = nil
+	w.AddToWorld(roid)
+	w.RemoveFromWorld(roid)
+	assert.Equal(t, []string{"roid begin", "roid/image begin"}, lifecycleLog)
+}
+
+func TestWorldEndPlay(t *testing.T) {
+	w := flat.NewWorld()
+	w.AddToWorld(newLifeActor("first"))
+	w.AddToWorld(newLifeActor("second", "second/image"))
+	lifecycleLog = nil
+	w.EndPlay()
+	assert.Equal(t, []string{"second end", "second/image end", "first end"}, lifecycleLog)
+	assert.Empty(t, flat.FindActorsByType[*lifeActor](w))
+}
+
+func TestLifeSpan(t *testing.T) {
+	lifecycleLog = nil
+	w := flat.NewWorld()
+	frame := flat.FrameTime()
+	bullet := newLifeActor("bullet")
+	bullet.LifeSpan = 2.5 * frame
+	roid := newLifeActor("roid")
+	w.AddToWorld(bullet)
+	w.AddToWorld(roid)
+	w.DestroyAfter(roid, 0.5*frame)
+
+	lifecycleLog = nil
+	w.Update()
+	assert.Equal(t, []string{"bullet update", "roid update", "roid end"}, lifecycleLog)
+	lifecycleLog = nil
+	w.Update()
+	w.Update()
+	assert.Equal(t, []string{"bullet update", "bullet update", "bullet end"}, lifecycleLog)
+}
+
+func TestSpawn(t *testing.T) {
+	m := asset.NewManager()
+	m.RegisterAsset(lifeActor{})
+	m.RegisterAsset(lifeComponent{})
+	w := flat.NewWorldWithManager(m)
+	template := newLifeActor("bullet", "bullet/image")
+
+	lifecycleLog = nil
+	at := flat.Transform{Location: vector3.Vector3{X: 10, Y: 20}, ScaleX: 1, ScaleY: 1}
+	spawned, err := w.Spawn(template, at)
+	assert.NoError(t, err)
+	assert.NotSame(t, template, spawned)
+	assert.Equal(t, at, *spawned.GetTransform())
+	assert.Equal(t, []string{"bullet begin", "bullet/image begin"}, lifecycleLog)
+
+	// spawning during an update waits until the update is done
+	lifecycleLog = nil
+	var fired flat.Actor
+	spawned.(*lifeActor).onUpdate = func() {
+		fired, err = w.Spawn(template, at)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bullet update"}, lifecycleLog)
+	}
+	w.Update()
+	assert.Equal(t, []string{"bullet update", "bullet begin", "bullet/image begin"}, lifecycleLog)
+	assert.Equal(t, []*lifeActor{spawned.(*lifeActor), fired.(*lifeActor)}, flat.FindActorsByType[*lifeActor](w))
+}
+
+func TestReloaded(t *testing.T) {
+	files := fstest.MapFS{
+		"bullet.json": {Data: []byte(`{"Type": "github.com/bradbev/flatland/src/flat_test.lifeActor", "Inner": {"Name": "bullet"}}`)},
+	}
+	newManager := func() *asset.Manager {
+		m := asset.NewManager()
+		m.RegisterAsset(lifeActor{})
+		m.RegisterAsset(lifeComponent{})
+		m.RegisterFileSystem(files, 0)
+		return m
+	}
+	editor, game := newManager(), newManager()
+	w := flat.NewWorldWithManager(game)
+	template, err := game.Load("bullet.json")
+	assert.NoError(t, err)
+	_, err = editor.Load("bullet.json")
+	assert.NoError(t, err)
+
+	at := flat.Transform{Location: vector3.Vector3{X: 10, Y: 20}, ScaleX: 1, ScaleY: 1}
+	old, err := w.Spawn(template.(flat.Actor), at)
+	assert.NoError(t, err)
+	other := newLifeActor("other")
+	w.AddToWorld(other)
+
+	files["bullet.json"].Data = []byte(`{"Type": "github.com/bradbev/flatland/src/flat_test.lifeActor", "Inner": {"Name": "rocket"}}`)
+	reloaded, err := editor.Reload("bullet.json")
+	assert.NoError(t, err)
+	lifecycleLog = nil
+	assert.NoError(t, w.Reloaded(editor, reloaded))
+
+	assert.Equal(t, "rocket", template.(*lifeActor).Name, "the world's own Manager follows the editor's reloads")
+	actors := flat.FindActorsByType[*lifeActor](w)
+	assert.Len(t, actors, 2)
+	assert.Same(t, other, actors[0], "actors that were not instanced are left alone")
+	assert.NotSame(t, old, actors[1])
+	assert.Equal(t, "rocket", actors[1].Name)
+	assert.Equal(t, at, *actors[1].GetTransform())
+	assert.Equal(t, []string{"bullet end", "rocket begin"}, lifecycleLog)
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime/debug"
 
+	"github.com/bradbev/flatland/src/asset"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -48,14 +49,11 @@ func ApplyTransform(transform *Transform, geom *ebiten.GeoM) {
 }
 
 // Apply all the transforms up the owning chain so that nested components
-// can have relative transforms
+// can have relative transforms.  The world transform is cached, see
+// WorldTransform.  Drawables use DrawContext.ScreenTransform, which also
+// applies the camera.
 func ApplyComponentTransforms(tr Transformer, geom *ebiten.GeoM) {
-	ApplyTransform(tr.GetTransform(), geom)
-	if comp, ok := tr.(Component); ok {
-		if owningTransformer, ok := comp.Owner().(Transformer); ok {
-			ApplyComponentTransforms(owningTransformer, geom)
-		}
-	}
+	geom.Concat(WorldTransform(tr))
 }
 
 func WalkUpComponentOwners(start Component, callback func(comp Component)) {
@@ -68,3 +66,10 @@ func WalkUpComponentOwners(start Component, callback func(comp Component)) {
 func TypeOf[T any]() reflect.Type {
 	return reflect.TypeOf((*T)(nil)).Elem()
 }
+
+func managerOrDefault(m *asset.Manager) *asset.Manager {
+	if m == nil {
+		return asset.DefaultManager()
+	}
+	return m
+}
@@ -0,0 +1,319 @@
+package flat
+
+import (
+	"math"
+	"sort"
+
+	"github.com/deeean/go-vector/vector2"
+	"golang.org/x/exp/slices"
+)
+
+// OverlapHandler is implemented by actors that want to know when their
+// colliders start and stop overlapping other colliders.  self is the
+// actor's collider, other is the collider it overlaps.
+type OverlapHandler interface {
+	BeginOverlap(self, other Collider)
+	EndOverlap(self, other Collider)
+}
+
+// BodyComponent moves its actor by its Velocity, in world units per second,
+// and turns it by AngularVelocity, in degrees per second.  Damping is the
+// fraction of the velocity that is lost each second.
+type BodyComponent struct {
+	ComponentBase
+	Velocity        vector2.Vector2
+	AngularVelocity float64
+	Mass            float64
+	Damping         float64
+}
+
+func (b *BodyComponent) DefaultInitialize() {
+	b.Mass = 1
+}
+
+// AddImpulse changes the velocity by the impulse x, y shared over the Mass
+func (b *BodyComponent) AddImpulse(x, y float64) {
+	mass := b.Mass
+	if mass <= 0 {
+		mass = 1
+	}
+	b.Velocity.X += x / mass
+	b.Velocity.Y += y / mass
+}
+
+func (b *BodyComponent) integrate(deltaseconds float64) {
+	actor := OwningActor(b)
+	if actor == nil {
+		return
+	}
+	t := actor.GetTransform()
+	t.Location.X += b.Velocity.X * deltaseconds
+	t.Location.Y += b.Velocity.Y * deltaseconds
+	if b.AngularVelocity != 0 {
+		t.AddRotation(b.AngularVelocity * deltaseconds)
+	}
+	TransformChanged(actor)
+	if b.Damping > 0 {
+		keep := math.Max(0, 1-b.Damping*deltaseconds)
+		b.Velocity.X *= keep
+		b.Velocity.Y *= keep
+		b.AngularVelocity *= keep
+	}
+}
+
+// OwningActor returns the actor that c is a component of, or c itself
+// when it is an Actor
+func OwningActor(c Component) Actor {
+	for ; c != nil; c = c.Owner() {
+		if actor, ok := c.(Actor); ok {
+			return actor
+		}
+	}
+	return nil
+}
+
+// defaultCellSize suits colliders that are about the size of a sprite
+const defaultCellSize = 128
+
+// Physics moves the bodies and finds the overlapping colliders of a World.
+// The broadphase puts colliders into a grid of CellSize squares and only
+// tests the colliders that share a square.
+type Physics struct {
+	CellSize float64
+
+	colliders []Collider
+	ids       map[Collider]int
+	nextID    int
+	bodies    []*BodyComponent
+	overlaps  map[colliderPair]bool
+}
+
+// colliderPair holds the ids of two overlapping colliders, lowest first
+type colliderPair struct {
+	a, b int
+}
+
+func newPhysics() *Physics {
+	return &Physics{
+		CellSize: defaultCellSize,
+		ids:      map[Collider]int{},
+		overlaps: map[colliderPair]bool{},
+	}
+}
+
+// add finds the colliders and bodies of actor
+func (p *Physics) add(actor Actor) {
+	WalkComponents(actor, func(target, parent Component) {
+		if collider, ok := target.(Collider); ok {
+			if _, exists := p.ids[collider]; !exists {
+				p.nextID++
+				p.ids[collider] = p.nextID
+				p.colliders = append(p.colliders, collider)
+			}
+		}
+		if body, ok := target.(*BodyComponent); ok && !slices.Contains(p.bodies, body) {
+			p.bodies = append(p.bodies, body)
+		}
+	})
+}
+
+// remove forgets the colliders and bodies of actor, and ends the overlaps
+// of its colliders
+func (p *Physics) remove(actor Actor) {
+	removed := map[int]bool{}
+	for _, c := range p.colliders {
+		if ownedBy(c, actor) {
+			removed[p.ids[c]] = true
+		}
+	}
+	p.bodies = slices.DeleteFunc(p.bodies, func(b *BodyComponent) bool {
+		return ownedBy(b, actor)
+	})
+	if len(removed) == 0 {
+		return
+	}
+	var ended []colliderPair
+	for pair := range p.overlaps {
+		if removed[pair.a] || removed[pair.b] {
+			ended = append(ended, pair)
+		}
+	}
+	sortPairs(ended)
+	for _, pair := range ended {
+		delete(p.overlaps, pair)
+	}
+	byID := p.byID()
+	p.colliders = slices.DeleteFunc(p.colliders, func(c Collider) bool {
+		return removed[p.ids[c]]
+	})
+	for id := range removed {
+		delete(p.ids, byID[id])
+	}
+	for _, pair := range ended {
+		a, b := byID[pair.a], byID[pair.b]
+		tellActor(a, b, false)
+		tellActor(b, a, false)
+	}
+}
+
+func (p *Physics) byID() map[int]Collider {
+	result := make(map[int]Collider, len(p.ids))
+	for c, id := range p.ids {
+		result[id] = c
+	}
+	return result
+}
+
+// Step moves the bodies on by deltaseconds, and then tells actors about
+// the overlaps that began and ended
+func (p *Physics) Step(deltaseconds float64) {
+	for _, body := range p.bodies {
+		body.integrate(deltaseconds)
+	}
+
+	current := p.findOverlaps()
+	var began, ended []colliderPair
+	for pair := range current {
+		if !p.overlaps[pair] {
+			began = append(began, pair)
+		}
+	}
+	for pair := range p.overlaps {
+		if !current[pair] {
+			ended = append(ended, pair)
+		}
+	}
+	sortPairs(began)
+	sortPairs(ended)
+	p.overlaps = current
+
+	byID := p.byID()
+	dispatch := func(pairs []colliderPair, begin bool) {
+		for _, pair := range pairs {
+			a, b := byID[pair.a], byID[pair.b]
+			// a handler may remove either collider from the world
+			if p.contains(a) && p.contains(b) {
+				tellActor(a, b, begin)
+			}
+			if p.contains(a) && p.contains(b) {
+				tellActor(b, a, begin)
+			}
+		}
+	}
+	dispatch(ended, false)
+	dispatch(began, true)
+}
+
+func (p *Physics) contains(c Collider) bool {
+	_, ok := p.ids[c]
+	return ok
+}
+
+// Overlaps returns the colliders that c overlapped at the last Step
+func (p *Physics) Overlaps(c Collider) []Collider {
+	id, ok := p.ids[c]
+	if !ok {
+		return nil
+	}
+	var pairs []colliderPair
+	for pair := range p.overlaps {
+		if pair.a == id || pair.b == id {
+			pairs = append(pairs, pair)
+		}
+	}
+	sortPairs(pairs)
+	byID := p.byID()
+	var result []Collider
+	for _, pair := range pairs {
+		if pair.a == id {
+			result = append(result, byID[pair.b])
+		} else {
+			result = append(result, byID[pair.a])
+		}
+	}
+	return result
+}
+
+type gridCell struct {
+	x, y int
+}
+
+// findOverlaps returns every pair of colliders that overlap now
+func (p *Physics) findOverlaps() map[colliderPair]bool {
+	cellSize := p.CellSize
+	if cellSize <= 0 {
+		cellSize = defaultCellSize
+	}
+	shapes := make([]shape, len(p.colliders))
+	grid := map[gridCell][]int{}
+	for i, c := range p.colliders {
+		shapes[i] = c.worldShape()
+		b := shapes[i].bounds
+		if math.IsInf(b.minX, 0) || math.IsNaN(b.minX) {
+			continue
+		}
+		minX, minY := int(math.Floor(b.minX/cellSize)), int(math.Floor(b.minY/cellSize))
+		maxX, maxY := int(math.Floor(b.maxX/cellSize)), int(math.Floor(b.maxY/cellSize))
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				cell := gridCell{x, y}
+				grid[cell] = append(grid[cell], i)
+			}
+		}
+	}
+
+	result := map[colliderPair]bool{}
+	tested := map[[2]int]bool{}
+	for _, inCell := range grid {
+		for n, i := range inCell {
+			for _, j := range inCell[n+1:] {
+				if tested[[2]int{i, j}] {
+					continue
+				}
+				tested[[2]int{i, j}] = true
+				a, b := p.colliders[i], p.colliders[j]
+				if !a.colliderBase().CollidesWith(b.colliderBase()) {
+					continue
+				}
+				if OwningActor(a) == OwningActor(b) {
+					continue
+				}
+				if shapes[i].overlap(&shapes[j]) {
+					result[p.pair(a, b)] = true
+				}
+			}
+		}
+	}
+	return result
+}
+
+func (p *Physics) pair(a, b Collider) colliderPair {
+	idA, idB := p.ids[a], p.ids[b]
+	if idA > idB {
+		idA, idB = idB, idA
+	}
+	return colliderPair{idA, idB}
+}
+
+func sortPairs(pairs []colliderPair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].a != pairs[j].a {
+			return pairs[i].a < pairs[j].a
+		}
+		return pairs[i].b < pairs[j].b
+	})
+}
+
+// tellActor tells the actor of self that it began or ended overlapping
+// other
+func tellActor(self, other Collider, begin bool) {
+	handler, ok := OwningActor(self).(OverlapHandler)
+	if !ok {
+		return
+	}
+	if begin {
+		handler.BeginOverlap(self, other)
+	} else {
+		handler.EndOverlap(self, other)
+	}
+}
@@ -0,0 +1,172 @@
+package flat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/deeean/go-vector/vector2"
+	"github.com/deeean/go-vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShapeOverlap(t *testing.T) {
+	square := func(x, y, size float64) shape {
+		return polygonShape(polygonKind, []vector2.Vector2{{X: x, Y: y}, {X: x + size, Y: y}, {X: x + size, Y: y + size}, {X: x, Y: y + size}})
+	}
+	box := func(x, y, size float64) shape {
+		s := square(x, y, size)
+		s.kind = boxKind
+		return s
+	}
+	diamond := polygonShape(polygonKind, []vector2.Vector2{{X: 0, Y: -10}, {X: 10, Y: 0}, {X: 0, Y: 10}, {X: -10, Y: 0}})
+
+	tests := []struct {
+		name string
+		a, b shape
+		want bool
+	}{
+		{"circles", circleShape(0, 0, 10), circleShape(15, 0, 10), true},
+		{"touching circles", circleShape(0, 0, 10), circleShape(20, 0, 10), false},
+		{"boxes", box(0, 0, 10), box(5, 5, 10), true},
+		{"touching boxes", box(0, 0, 10), box(10, 0, 10), false},
+		{"circle in the corner of the diamond's bounds", circleShape(9, 9, 3), diamond, false},
+		{"circle on the diamond's edge", circleShape(6, 6, 3), diamond, true},
+		{"square and diamond", square(4, 4, 10), diamond, true},
+		{"square beside the diamond", square(6, 6, 10), diamond, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.a.overlap(&test.b), test.name)
+		assert.Equal(t, test.want, test.b.overlap(&test.a), test.name+" swapped")
+	}
+}
+
+func TestColliderShapes(t *testing.T) {
+	a := &EmptyActor{}
+	a.Transform.DefaultInitialize()
+	a.Transform.ScaleX, a.Transform.ScaleY = 2, 2
+	a.Transform.Rotation = 90
+	circle := &CircleCollider{Radius: 5}
+	circle.Transform.DefaultInitialize()
+	circle.Transform.Location.X = 10
+	box := &BoxCollider{Width: 4, Height: 2}
+	box.Transform.DefaultInitialize()
+	poly := &PolygonCollider{Points: []vector2.Vector2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}}
+	poly.Transform.DefaultInitialize()
+	a.Components = []Component{circle, box, poly}
+	a.BeginPlay()
+
+	s := circle.worldShape()
+	assertPoint(t, 0, 20, s.x, s.y)
+	assert.InDelta(t, 10, s.radius, 1e-9)
+
+	// boxes scale, but do not turn
+	assert.Equal(t, bounds{-4, -2, 4, 2}, box.worldShape().bounds)
+
+	s = poly.worldShape()
+	assertPoint(t, 0, 2, s.points[1].X, s.points[1].Y)
+	assertPoint(t, -2, 0, s.points[2].X, s.points[2].Y)
+}
+
+type overlapActor struct {
+	ActorBase
+	name   string
+	events *[]string
+	onHit  func(other Collider)
+}
+
+func (a *overlapActor) BeginPlay() { a.ActorBase.BeginPlay(a) }
+func (a *overlapActor) BeginOverlap(self, other Collider) {
+	*a.events = append(*a.events, fmt.Sprintf("%s begin %s", a.name, OwningActor(other).(*overlapActor).name))
+	if a.onHit != nil {
+		a.onHit(other)
+	}
+}
+func (a *overlapActor) EndOverlap(self, other Collider) {
+	*a.events = append(*a.events, fmt.Sprintf("%s end %s", a.name, OwningActor(other).(*overlapActor).name))
+}
+
+func newOverlapActor(events *[]string, name string, x float64, group string, mask ...string) (*overlapActor, *CircleCollider) {
+	collider := &CircleCollider{Radius: 10}
+	collider.Transform.DefaultInitialize()
+	collider.Group = group
+	collider.Mask = mask
+	a := &overlapActor{name: name, events: events}
+	a.Transform.DefaultInitialize()
+	a.Transform.Location = vector3.Vector3{X: x}
+	a.Components = []Component{collider}
+	return a, collider
+}
+
+func TestWorldOverlaps(t *testing.T) {
+	events := []string{}
+	w := NewWorld()
+	ship, shipCollider := newOverlapActor(&events, "ship", 0, "ship", "roid")
+	roid, _ := newOverlapActor(&events, "roid", 500, "roid")
+	otherShip, _ := newOverlapActor(&events, "otherShip", 5, "ship", "roid")
+	w.AddToWorld(ship)
+	w.AddToWorld(roid)
+	w.AddToWorld(otherShip)
+
+	w.Update()
+	assert.Empty(t, events, "the ships are not in each other's masks")
+
+	// the roid crosses cells of the broadphase to reach the ship
+	roid.Transform.Location.X = 15
+	TransformChanged(roid)
+	w.Update()
+	assert.Equal(t, []string{"ship begin roid", "roid begin ship", "roid begin otherShip", "otherShip begin roid"}, events)
+	assert.Len(t, w.Physics().Overlaps(shipCollider), 1)
+
+	events = events[:0]
+	w.Update()
+	assert.Empty(t, events, "overlaps begin once")
+
+	roid.Transform.Location.X = 500
+	TransformChanged(roid)
+	w.Update()
+	assert.Equal(t, []string{"ship end roid", "roid end ship", "roid end otherShip", "otherShip end roid"}, events)
+	assert.Empty(t, w.Physics().Overlaps(shipCollider))
+}
+
+func TestRemoveWhileOverlapping(t *testing.T) {
+	events := []string{}
+	w := NewWorld()
+	bullet, _ := newOverlapActor(&events, "bullet", 0, "bullet", "roid")
+	roid, _ := newOverlapActor(&events, "roid", 5, "roid")
+	bullet.onHit = func(other Collider) {
+		w.RemoveFromWorld(OwningActor(other))
+		w.RemoveFromWorld(bullet)
+	}
+	w.AddToWorld(bullet)
+	w.AddToWorld(roid)
+	w.Update()
+	assert.Equal(t, []string{"bullet begin roid", "bullet end roid", "roid end bullet"}, events,
+		"removing an actor ends its overlaps, and it hears of nothing else")
+	assert.Empty(t, w.Physics().colliders)
+}
+
+func TestBody(t *testing.T) {
+	body := &BodyComponent{}
+	body.Transform.DefaultInitialize()
+	body.DefaultInitialize()
+	body.Mass = 2
+	a := &EmptyActor{}
+	a.Transform.DefaultInitialize()
+	a.Components = []Component{body}
+
+	w := NewWorld()
+	w.AddToWorld(a)
+	body.AddImpulse(20, -10)
+	body.AngularVelocity = 90
+	w.Physics().Step(0.5)
+	assertPoint(t, 5, -2.5, a.Transform.Location.X, a.Transform.Location.Y)
+	assert.InDelta(t, 45, a.Transform.Rotation, 1e-9)
+
+	body.Damping = 1
+	w.Physics().Step(0.5)
+	assertPoint(t, 5, -2.5, body.Velocity.X, body.Velocity.Y)
+
+	w.RemoveFromWorld(a)
+	w.Physics().Step(0.5)
+	assertPoint(t, 10, -5, a.Transform.Location.X, a.Transform.Location.Y)
+}
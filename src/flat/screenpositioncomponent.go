@@ -1,7 +1,5 @@
 package flat
 
-import "github.com/hajimehoshi/ebiten/v2"
-
 type PositionAnchor int
 
 const (
@@ -25,8 +23,8 @@ type ScreenPositionComponent struct {
 	YPixelsAwayFromAnchor  int32
 }
 
-func (s *ScreenPositionComponent) Draw(screen *ebiten.Image) {
-	bounds := screen.Bounds()
+func (s *ScreenPositionComponent) Draw(ctx *DrawContext) {
+	bounds := ctx.Screen.Bounds()
 	var startX, startY float32
 	dirX, dirY := float32(1), float32(1)
 
@@ -56,9 +54,17 @@ func (s *ScreenPositionComponent) Draw(screen *ebiten.Image) {
 		dirX, dirY = -1, -1
 	}
 
-	x := startX + dirX*(float32(s.XPixelsAwayFromAnchor)+float32(bounds.Dx())*s.XPercentAwayFromAnchor/100)
-	y := startY + dirY*(float32(s.YPixelsAwayFromAnchor)+float32(bounds.Dy())*s.YPercentAwayFromAnchor/100)
+	x := float32(bounds.Min.X) + startX + dirX*(float32(s.XPixelsAwayFromAnchor)+float32(bounds.Dx())*s.XPercentAwayFromAnchor/100)
+	y := float32(bounds.Min.Y) + startY + dirY*(float32(s.YPixelsAwayFromAnchor)+float32(bounds.Dy())*s.YPercentAwayFromAnchor/100)
+
+	// the position is on screen, keep it there when a camera moves
+	toWorld := ctx.View
+	if toWorld.IsInvertible() {
+		toWorld.Invert()
+	}
+	worldX, worldY := toWorld.Apply(float64(x), float64(y))
 	t := s.GetTransform()
-	t.Location.X = float64(x)
-	t.Location.Y = float64(y)
+	t.Location.X = worldX
+	t.Location.Y = worldY
+	TransformChanged(s)
 }
@@ -47,7 +47,7 @@ func (t *TextComponent) SetValues(data any) {
 	t.tmpl.Execute(&t.lastEval, data)
 }
 
-func (t *TextComponent) Draw(screen *ebiten.Image) {
+func (t *TextComponent) Draw(ctx *DrawContext) {
 	if t.Font == nil {
 		return
 	}
@@ -60,8 +60,9 @@ func (t *TextComponent) Draw(screen *ebiten.Image) {
 			t.op.GeoM.Scale(transform.ScaleX, transform.ScaleY)
 			t.op.GeoM.Translate(transform.Location.X, transform.Location.Y)
 		})
+		t.op.GeoM.Concat(ctx.View)
 	} else {
-		ApplyComponentTransforms(t, &t.op.GeoM)
+		t.op.GeoM.Concat(ctx.ScreenTransform(t))
 	}
-	text.DrawWithOptions(screen, t.lastEval.String(), t.Font.face, &t.op)
+	text.DrawWithOptions(ctx.Screen, t.lastEval.String(), t.Font.face, &t.op)
 }
@@ -0,0 +1,144 @@
+package flat
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Components are positioned relative to their owner, so the world transform
+// of a component is its own Transform followed by the world transform of its
+// owner.  ComponentBase and ActorBase cache their world transform until it
+// is marked dirty, so a cached world transform is returned without looking
+// at the owners.  Change a Transform with SetTransform, or call
+// TransformChanged after changing its fields, which marks the transformer
+// and every component under it dirty.  Code that changes Transforms
+// without knowing what holds them, such as an editor, calls
+// InvalidateTransforms.
+
+// transformGeneration is bumped by InvalidateTransforms, a cache from an
+// earlier generation is dirty
+var transformGeneration atomic.Uint64
+
+type transformCache struct {
+	valid      bool
+	generation uint64
+	world      ebiten.GeoM
+}
+
+// cachedTransformer is implemented by ComponentBase and ActorBase, and by
+// every type that embeds them
+type cachedTransformer interface {
+	worldTransformCache() *transformCache
+}
+
+func (c *ComponentBase) worldTransformCache() *transformCache { return &c.transformCache }
+func (a *ActorBase) worldTransformCache() *transformCache     { return &a.transformCache }
+
+// SetTransform sets the Transform of tr and marks it dirty, see
+// TransformChanged
+func SetTransform(tr Transformer, transform Transform) {
+	*tr.GetTransform() = transform
+	TransformChanged(tr)
+}
+
+// TransformChanged marks the cached world transform of tr, and of every
+// component under it, dirty.  Call it after changing the fields of tr's
+// Transform.
+func TransformChanged(tr Transformer) {
+	if cached, ok := tr.(cachedTransformer); ok {
+		cached.worldTransformCache().valid = false
+	}
+	if comp, ok := tr.(Component); ok {
+		for _, child := range comp.GetComponents() {
+			if child != nil {
+				TransformChanged(child)
+			}
+		}
+	}
+}
+
+// InvalidateTransforms marks every cached world transform dirty
+func InvalidateTransforms() {
+	transformGeneration.Add(1)
+}
+
+// LocalTransform returns the matrix for transform on its own, scale then
+// rotate then translate
+func LocalTransform(transform *Transform) ebiten.GeoM {
+	g := ebiten.GeoM{}
+	ApplyTransform(transform, &g)
+	return g
+}
+
+// WorldTransform returns the matrix that takes the local coordinates of tr
+// to world coordinates
+func WorldTransform(tr Transformer) ebiten.GeoM {
+	return worldTransform(tr)
+}
+
+// worldTransform returns the world transform of tr, from its cache when
+// it is not dirty
+func worldTransform(tr Transformer) ebiten.GeoM {
+	generation := transformGeneration.Load()
+	var cache *transformCache
+	if cached, ok := tr.(cachedTransformer); ok {
+		cache = cached.worldTransformCache()
+		if cache.valid && cache.generation == generation {
+			return cache.world
+		}
+	}
+
+	world := LocalTransform(tr.GetTransform())
+	if comp, ok := tr.(Component); ok {
+		if owningTransformer, ok := comp.Owner().(Transformer); ok && owningTransformer != nil {
+			world.Concat(worldTransform(owningTransformer))
+		}
+	}
+	if cache != nil {
+		*cache = transformCache{valid: true, generation: generation, world: world}
+	}
+	return world
+}
+
+// InverseWorldTransform returns the matrix that takes world coordinates to
+// the local coordinates of tr.  ok is false when tr is scaled to nothing
+// and has no inverse.
+func InverseWorldTransform(tr Transformer) (inverse ebiten.GeoM, ok bool) {
+	inverse = WorldTransform(tr)
+	if !inverse.IsInvertible() {
+		return ebiten.GeoM{}, false
+	}
+	inverse.Invert()
+	return inverse, true
+}
+
+// LocalToWorld returns the world position of the point x, y in the local
+// coordinates of tr
+func LocalToWorld(tr Transformer, x, y float64) (float64, float64) {
+	world := WorldTransform(tr)
+	return world.Apply(x, y)
+}
+
+// WorldToLocal returns the position of the world point x, y in the local
+// coordinates of tr, for example to find where the mouse is on a component
+func WorldToLocal(tr Transformer, x, y float64) (float64, float64) {
+	inverse, ok := InverseWorldTransform(tr)
+	if !ok {
+		return 0, 0
+	}
+	return inverse.Apply(x, y)
+}
+
+// WorldPosition returns the world position of the origin of tr
+func WorldPosition(tr Transformer) (x, y float64) {
+	return LocalToWorld(tr, 0, 0)
+}
+
+// WorldRotation returns the rotation of tr in the world, in degrees in the
+// range -180..180.  It is the direction that the local X axis points in.
+func WorldRotation(tr Transformer) float64 {
+	world := WorldTransform(tr)
+	return math.Atan2(world.Element(1, 0), world.Element(0, 0)) * 180 / math.Pi
+}
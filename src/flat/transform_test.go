@@ -0,0 +1,94 @@
+package flat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/deeean/go-vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+type transformTestComponent struct{ ComponentBase }
+
+func newTransformTestActor() (*EmptyActor, *transformTestComponent, *transformTestComponent) {
+	inner := &transformTestComponent{}
+	inner.Transform.DefaultInitialize()
+	outer := &transformTestComponent{}
+	outer.Transform.DefaultInitialize()
+	outer.Children = []Component{inner}
+	a := &EmptyActor{}
+	a.Transform.DefaultInitialize()
+	a.Components = []Component{outer}
+	a.BeginPlay()
+	return a, outer, inner
+}
+
+func assertPoint(t *testing.T, wantX, wantY, x, y float64) {
+	t.Helper()
+	assert.InDelta(t, wantX, x, 1e-9)
+	assert.InDelta(t, wantY, y, 1e-9)
+}
+
+func TestWorldTransform(t *testing.T) {
+	a, outer, inner := newTransformTestActor()
+	a.Transform.Location = vector3.Vector3{X: 100, Y: 50}
+	outer.Transform.Rotation = 90
+	inner.Transform.Location = vector3.Vector3{X: 10}
+	inner.Transform.ScaleX, inner.Transform.ScaleY = 2, 2
+
+	x, y := WorldPosition(inner)
+	assertPoint(t, 100, 60, x, y)
+	assert.InDelta(t, 90, WorldRotation(inner), 1e-9)
+	x, y = LocalToWorld(inner, 1, 0)
+	assertPoint(t, 100, 62, x, y)
+
+	x, y = WorldToLocal(inner, 100, 62)
+	assertPoint(t, 1, 0, x, y)
+
+	// matches applying each transform up the owners by hand
+	world := WorldTransform(inner)
+	x, y = world.Apply(3, 4)
+	g := LocalTransform(&inner.Transform)
+	g.Concat(LocalTransform(&outer.Transform))
+	g.Concat(LocalTransform(&a.Transform))
+	wantX, wantY := g.Apply(3, 4)
+	assertPoint(t, wantX, wantY, x, y)
+}
+
+func TestWorldTransformCache(t *testing.T) {
+	a, outer, inner := newTransformTestActor()
+	WorldTransform(inner)
+	assert.True(t, inner.transformCache.valid)
+	assert.True(t, outer.transformCache.valid, "the owners are cached on the way")
+
+	// a cached world transform is used without looking at the owners, so
+	// changing a field is only seen once the change is marked
+	a.Transform.Location.X = 20
+	x, _ := WorldPosition(inner)
+	assert.InDelta(t, 0, x, 1e-9)
+	TransformChanged(a)
+	assert.False(t, inner.transformCache.valid, "marking an owner dirties everything below it")
+	x, _ = WorldPosition(inner)
+	assert.InDelta(t, 20, x, 1e-9)
+
+	moved := outer.Transform
+	moved.AddRotation(45)
+	SetTransform(outer, moved)
+	assert.True(t, a.transformCache.valid, "the owners above stay cached")
+	assert.InDelta(t, 45, WorldRotation(inner), 1e-9)
+
+	// InvalidateTransforms dirties every cache
+	inner.Transform.Location.X = 10
+	InvalidateTransforms()
+	x, _ = WorldPosition(inner)
+	assert.InDelta(t, 20+10*math.Cos(math.Pi/4), x, 1e-9)
+}
+
+func TestWorldToLocalScaledToNothing(t *testing.T) {
+	_, outer, inner := newTransformTestActor()
+	outer.Transform.ScaleX = 0
+	_, ok := InverseWorldTransform(inner)
+	assert.False(t, ok)
+	x, y := WorldToLocal(inner, 5, 5)
+	assertPoint(t, 0, 0, x, y)
+}
@@ -3,13 +3,24 @@ package flat
 import "github.com/bradbev/flatland/src/asset"
 
 func RegisterAllFlatTypes() {
-	asset.RegisterAsset(Image{})
-	asset.RegisterAsset(World{})
-	asset.RegisterAsset(ImageComponent{})
-	asset.RegisterAsset(Font{})
-	asset.RegisterAsset(ActorBase{})
-	asset.RegisterAsset(EmptyActor{})
-	asset.RegisterAsset(TextComponent{})
-	asset.RegisterAsset(ScreenPositionComponent{})
-	asset.RegisterAsset(MouseEventComponent{})
+	RegisterAllFlatTypesWithManager(asset.DefaultManager())
+}
+
+// RegisterAllFlatTypesWithManager registers the flat types with a
+// specific asset.Manager
+func RegisterAllFlatTypesWithManager(assets *asset.Manager) {
+	assets.RegisterAsset(Image{})
+	assets.RegisterAsset(World{})
+	assets.RegisterAsset(ImageComponent{})
+	assets.RegisterAsset(Font{})
+	assets.RegisterAsset(ActorBase{})
+	assets.RegisterAsset(EmptyActor{})
+	assets.RegisterAsset(TextComponent{})
+	assets.RegisterAsset(ScreenPositionComponent{})
+	assets.RegisterAsset(MouseEventComponent{})
+	assets.RegisterAsset(CameraComponent{})
+	assets.RegisterAsset(CircleCollider{})
+	assets.RegisterAsset(BoxCollider{})
+	assets.RegisterAsset(PolygonCollider{})
+	assets.RegisterAsset(BodyComponent{})
 }
@@ -2,6 +2,7 @@ package flat
 
 import (
 	"errors"
+	"image"
 
 	"github.com/bradbev/flatland/src/asset"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -12,15 +13,53 @@ type World struct {
 	actors           []Actor
 	updateables      []Updateable
 	drawables        []Drawable
+	cameras          []*CameraComponent
+	orderedDrawables []Drawable
+	hiddenLayers     map[Layer]bool
+	physics          *Physics
+	lifeSpans        map[Actor]float64
+	// templates are the assets that actors were instanced from, so that
+	// they can be instanced again when the assets are reloaded
+	templates        map[Actor]Actor
 	PersistentActors []Actor `flat:"inline"`
+
+	// screen is the size of the screen the world was last drawn to, see
+	// screenBounds
+	screen image.Rectangle
+
+	// ticking is above 0 while the world updates or draws.  Actors that
+	// are added or removed then wait in pending until it is done.
+	ticking int
+	pending []pendingChange
+
+	// assets creates the actor instances when play begins.  When nil the
+	// asset.DefaultManager is used.
+	assets *asset.Manager
 }
 
 func NewWorld() *World {
-	w := &World{}
+	return NewWorldWithManager(nil)
+}
+
+// NewWorldWithManager creates a World that instances its actors from
+// the given asset Manager.
+func NewWorldWithManager(assets *asset.Manager) *World {
+	w := &World{assets: assets}
 	w.reset()
 	return w
 }
 
+// SetAssetManager implements asset.ManagerAwareAsset so that worlds
+// loaded by a Manager create their actors with that Manager.
+func (w *World) SetAssetManager(assets *asset.Manager) {
+	w.assets = assets
+}
+
+// AssetManager returns the Manager this World uses.
+func (w *World) AssetManager() *asset.Manager {
+	return managerOrDefault(w.assets)
+}
+
 func FindActorsByType[T Actor](world *World) []T {
 	var result []T
 	for _, a := range world.actors {
@@ -47,6 +86,11 @@ func (w *World) reset() {
 	w.actors = nil
 	w.drawables = nil
 	w.updateables = nil
+	w.cameras = nil
+	w.physics = nil
+	w.pending = nil
+	w.lifeSpans = nil
+	w.templates = nil
 }
 
 func (w *World) PostLoad() {
@@ -69,17 +113,38 @@ func (w *World) beginPlay(isEditor bool) {
 		if isEditor {
 			w.AddToWorld(actor)
 		} else {
-			instance, _ := asset.NewInstance(actor)
+			instance, _ := w.AssetManager().NewInstance(actor)
+			w.setTemplate(instance.(Actor), actor)
 			w.AddToWorld(instance.(Actor))
 		}
 	}
 }
 
+// EndPlay ends the play of every actor, the last added first, and empties
+// the world
 func (w *World) EndPlay() {
+	actors := slices.Clone(w.actors)
 	w.reset()
+	for i := len(actors) - 1; i >= 0; i-- {
+		if endPlayable, ok := actors[i].(EndPlayable); ok {
+			endPlayable.EndPlay()
+		}
+	}
 }
 
+// AddToWorld adds actor to the world and begins its play.  While the world
+// updates or draws the actor is added when the tick is done.
 func (w *World) AddToWorld(actor Actor) {
+	if w.ticking > 0 {
+		w.pending = append(w.pending, pendingChange{actor: actor, add: true})
+		return
+	}
+	w.addNow(actor)
+}
+
+func (w *World) addNow(actor Actor) {
+	// the actor may have been moved or reloaded since it was last drawn
+	TransformChanged(actor)
 	w.actors = append(w.actors, actor)
 	if updateable, ok := actor.(Updateable); ok {
 		w.updateables = append(w.updateables, updateable)
@@ -90,9 +155,35 @@ func (w *World) AddToWorld(actor Actor) {
 	if playable, ok := actor.(Playable); ok {
 		playable.BeginPlay()
 	}
+	WalkComponents(actor, func(target, parent Component) {
+		if camera, ok := target.(*CameraComponent); ok {
+			w.cameras = append(w.cameras, camera)
+		}
+	})
+	w.Physics().add(actor)
+	if spanned, ok := actor.(lifeSpanned); ok && spanned.lifeSpan() > 0 {
+		w.DestroyAfter(actor, spanned.lifeSpan())
+	}
 }
 
+// RemoveFromWorld takes actor out of the world without ending its play,
+// see Destroy.  While the world updates or draws the actor is removed when
+// the tick is done.
 func (w *World) RemoveFromWorld(actor Actor) {
+	if w.ticking > 0 {
+		w.queueRemove(actor, false)
+		return
+	}
+	w.removeNow(actor)
+}
+
+// removeNow takes actor out of the world and reports whether it was in it
+func (w *World) removeNow(actor Actor) bool {
+	if !slices.Contains(w.actors, actor) {
+		return false
+	}
+	delete(w.lifeSpans, actor)
+	delete(w.templates, actor)
 	w.actors = slices.DeleteFunc(w.actors, func(a Actor) bool {
 		return a == actor
 	})
@@ -106,16 +197,174 @@ func (w *World) RemoveFromWorld(actor Actor) {
 			return d == drawable
 		})
 	}
+	w.cameras = slices.DeleteFunc(w.cameras, func(c *CameraComponent) bool {
+		return ownedBy(c, actor)
+	})
+	w.Physics().remove(actor)
+	return true
 }
 
+// Physics returns the collision and movement of the world's actors
+func (w *World) Physics() *Physics {
+	if w.physics == nil {
+		w.physics = newPhysics()
+	}
+	return w.physics
+}
+
+// ownedBy reports whether actor is c or one of c's owners
+func ownedBy(c Component, actor Actor) bool {
+	for ; c != nil; c = c.Owner() {
+		if c == Component(actor) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update updates the actors, then moves the bodies and tells the actors
+// about overlaps.  Actors that were spawned or destroyed during the update
+// are added and removed at the end.
 func (w *World) Update() {
+	w.ticking++
 	for _, updateable := range w.updateables {
 		updateable.Update()
 	}
+	w.updateLifeSpans(FrameTime())
+	w.Physics().Step(FrameTime())
+	w.endTick()
 }
 
+// Draw draws the world once for each Active camera, into the camera's
+// viewport.  Without a camera the world is drawn straight to the screen.
+// Actors are drawn lowest Layer first.
 func (w *World) Draw(screen *ebiten.Image) {
-	for _, drawable := range w.drawables {
-		drawable.Draw(screen)
+	w.ticking++
+	w.screen = screen.Bounds()
+	for _, v := range w.views(w.screen) {
+		w.drawView(screen, v)
+	}
+	w.endTick()
+}
+
+// DrawWithCamera draws the world through camera, rather than through the
+// cameras in the world.  The editor uses it to pan and zoom.
+func (w *World) DrawWithCamera(screen *ebiten.Image, camera *Camera) {
+	w.ticking++
+	w.screen = screen.Bounds()
+	w.drawView(screen, worldView{
+		rect: camera.Viewport.Rect(w.screen),
+		geom: camera.GeoM(w.screen),
+	})
+	w.endTick()
+}
+
+func (w *World) drawView(screen *ebiten.Image, v worldView) {
+	target := screen
+	if v.rect != screen.Bounds() {
+		target = screen.SubImage(v.rect).(*ebiten.Image)
 	}
+	ctx := &DrawContext{Screen: target, View: v.geom, hiddenLayers: w.hiddenLayers}
+	w.orderedDrawables = drawOrder(ctx, w.orderedDrawables, w.drawables)
+	for _, drawable := range w.orderedDrawables {
+		drawable.Draw(ctx)
+	}
+}
+
+// SetLayerVisible shows or hides a layer when this world is drawn
+func (w *World) SetLayerVisible(layer Layer, visible bool) {
+	if w.hiddenLayers == nil {
+		w.hiddenLayers = map[Layer]bool{}
+	}
+	if visible {
+		delete(w.hiddenLayers, layer)
+	} else {
+		w.hiddenLayers[layer] = true
+	}
+}
+
+// IsLayerVisible reports whether layer is drawn by this world
+func (w *World) IsLayerVisible(layer Layer) bool {
+	return !w.hiddenLayers[layer]
+}
+
+type worldView struct {
+	rect image.Rectangle
+	geom ebiten.GeoM
+}
+
+// views returns the viewports that the world is drawn into on screen
+func (w *World) views(screen image.Rectangle) []worldView {
+	var views []worldView
+	for _, c := range w.cameras {
+		if !c.Active {
+			continue
+		}
+		camera := c.Camera()
+		views = append(views, worldView{
+			rect: camera.Viewport.Rect(screen),
+			geom: camera.GeoM(screen),
+		})
+	}
+	if len(views) == 0 {
+		views = append(views, worldView{rect: screen})
+	}
+	return views
+}
+
+func (w *World) hasActiveCamera() bool {
+	for _, c := range w.cameras {
+		if c.Active {
+			return true
+		}
+	}
+	return false
+}
+
+// Cameras returns the camera components of the actors in the world
+func (w *World) Cameras() []*CameraComponent {
+	return w.cameras
+}
+
+// screenBounds returns the size of the screen the world was last drawn to.
+// Before the first Draw that is not known, so the size of the window is
+// used instead.
+func (w *World) screenBounds() image.Rectangle {
+	if !w.screen.Empty() {
+		return w.screen
+	}
+	width, height := ebiten.WindowSize()
+	return image.Rect(0, 0, width, height)
+}
+
+// ScreenToWorld returns the world point under the screen point x, y, using
+// the camera whose viewport holds the point.  ok is false when no viewport
+// does.  Before the world is first drawn the viewports are placed on a
+// screen the size of the window.
+func (w *World) ScreenToWorld(x, y float64) (worldX, worldY float64, ok bool) {
+	if !w.hasActiveCamera() {
+		return x, y, true
+	}
+	views := w.views(w.screenBounds())
+	for i := len(views) - 1; i >= 0; i-- {
+		v := views[i]
+		if !image.Pt(int(x), int(y)).In(v.rect) {
+			continue
+		}
+		toWorld := v.geom
+		if !toWorld.IsInvertible() {
+			return 0, 0, false
+		}
+		toWorld.Invert()
+		worldX, worldY = toWorld.Apply(x, y)
+		return worldX, worldY, true
+	}
+	return 0, 0, false
+}
+
+// WorldToScreen returns where the world point x, y is drawn by the first
+// camera in the world
+func (w *World) WorldToScreen(x, y float64) (float64, float64) {
+	v := w.views(w.screenBounds())[0]
+	return v.geom.Apply(x, y)
 }
@@ -0,0 +1,168 @@
+// Package flatcmd holds the command line tools that operate on a content
+// folder.  The tools need the game's asset types (and migrations) to be
+// registered, so each game provides a small main that registers its types
+// and then calls into this package, for example
+//
+//	func main() {
+//		flat.RegisterAllFlatTypes()
+//		mygame.RegisterTypes()
+//		os.Exit(flatcmd.Upgrade(os.Args[1:]))
+//	}
+package flatcmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bradbev/flatland/src/asset"
+)
+
+// Upgrade migrates every asset in a content folder to the current version
+// of its type, in place.  With -ids it also gives every asset an ID, and
+// adds the IDs to references, for content saved before assets had IDs.
+// With -resave every asset is saved again, so that content saved before
+// json was canonical, or edited by hand, is canonical.  args are the
+// command line arguments, the return value is the process exit code.
+func Upgrade(args []string) int {
+	flags := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	content := flags.String("content", "./content", "content folder to upgrade")
+	ids := flags.Bool("ids", false, "give assets without an ID one")
+	resave := flags.Bool("resave", false, "save every asset again as canonical json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	asset.RegisterFileSystem(os.DirFS(*content), 0)
+	asset.RegisterWritableFileSystem(asset.NewWritableFS(asset.Path(*content)))
+
+	upgraded, err := asset.Upgrade()
+	for _, path := range upgraded {
+		fmt.Printf("upgraded %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "upgrade failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("%d assets upgraded\n", len(upgraded))
+
+	if *ids {
+		stamped, err := asset.StampIDs()
+		for _, path := range stamped {
+			fmt.Printf("stamped %s\n", path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "stamping IDs failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%d assets stamped with IDs\n", len(stamped))
+	}
+
+	if *resave {
+		resaved, err := asset.Resave()
+		for _, path := range resaved {
+			fmt.Printf("resaved %s\n", path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "resave failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%d assets resaved\n", len(resaved))
+	}
+	return 0
+}
+
+// Check loads every asset in a content folder and prints the problems it
+// finds, one per line, as "file: field: problem".  The exit code is 1 if
+// there are any problems, so Check can fail a CI build.
+func Check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	content := flags.String("content", "./content", "content folder to check")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	asset.RegisterFileSystem(os.DirFS(*content), 0)
+
+	problems := asset.Check()
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found\n", len(problems))
+		return 1
+	}
+	fmt.Println("no problems found")
+	return 0
+}
+
+// Package writes only the files that the root assets need, the assets they
+// refer to and the images, fonts, etc those use, to a directory or, when
+// -out ends in .zip, to a single archive.  The roots are the arguments
+// after the flags.  The exit code is 1 if any referenced file is missing.
+func Package(args []string) int {
+	flags := flag.NewFlagSet("package", flag.ContinueOnError)
+	content := flags.String("content", "./content", "content folder to package")
+	out := flags.String("out", "./packaged", "directory, or .zip archive, to write the package to")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "package needs at least one root asset, for example gameflow.json")
+		return 2
+	}
+	var roots []asset.Path
+	for _, root := range flags.Args() {
+		roots = append(roots, asset.Path(root))
+	}
+
+	asset.RegisterFileSystem(os.DirFS(*content), 0)
+
+	var packaged []asset.Path
+	var err error
+	if strings.HasSuffix(*out, ".zip") {
+		var f *os.File
+		f, err = os.Create(*out)
+		if err == nil {
+			packaged, err = asset.PackageArchive(f, roots...)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(*out)
+			}
+		}
+	} else {
+		packaged, err = asset.Package(asset.NewWritableFS(asset.Path(*out)), roots...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "package failed:\n%v\n", err)
+		return 1
+	}
+	for _, path := range packaged {
+		fmt.Printf("packaged %s\n", path)
+	}
+	fmt.Printf("%d files packaged\n", len(packaged))
+	return 0
+}
+
+// Cook converts every asset in a content folder to the binary format and
+// writes them, with the other files copied as-is, to the -out directory.
+// Asset files keep their names.  The exit code is 1 if cooking fails.
+func Cook(args []string) int {
+	flags := flag.NewFlagSet("cook", flag.ContinueOnError)
+	content := flags.String("content", "./content", "content folder to cook")
+	out := flags.String("out", "./cooked", "directory to write cooked content to")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	asset.RegisterFileSystem(os.DirFS(*content), 0)
+
+	if err := asset.Cook(asset.NewWritableFS(asset.Path(*out))); err != nil {
+		fmt.Fprintf(os.Stderr, "cook failed: %v\n", err)
+		return 1
+	}
+	return 0
+}